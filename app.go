@@ -25,13 +25,15 @@
 package grumble
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
+	"text/template"
 
 	"github.com/desertbit/closer/v4"
-	shlex "github.com/desertbit/go-shlex"
 	"github.com/desertbit/readline"
 	"github.com/fatih/color"
 )
@@ -41,11 +43,18 @@ type App struct {
 	closer.Closer
 
 	rl            *readline.Instance
+	autosuggester *autosuggester
+	variables     map[string]string
+	aliases       map[string]string
+	in            *readline.Instance // Reads prompt input, also in non-interactive mode.
 	config        *Config
 	commands      Commands
 	isShell       bool
 	currentPrompt string
 
+	recorder      *recorder
+	recorderMutex sync.Mutex
+
 	flags   Flags
 	flagMap FlagMap
 
@@ -54,10 +63,12 @@ type App struct {
 	initHook  func(a *App, flags FlagMap) error
 	shellHook func(a *App) error
 
-	printHelp        func(a *App, shell bool)
-	printCommandHelp func(a *App, cmd *Command, shell bool)
-	interruptHandler func(a *App, count int)
-	printASCIILogo   func(a *App)
+	printHelp           func(a *App, shell bool)
+	printCommandHelp    func(a *App, cmd *Command, shell bool)
+	helpTemplate        *template.Template
+	commandHelpTemplate *template.Template
+	interruptHandler    func(a *App, count int)
+	printASCIILogo      func(a *App)
 }
 
 // New creates a new app.
@@ -83,10 +94,22 @@ func New(c *Config) (a *App) {
 	if c.InterruptHandler != nil {
 		a.interruptHandler = c.InterruptHandler
 	}
+	a.commands.SetAbbreviations(c.CommandAbbreviations)
+
+	// Parse the help templates.
+	a.helpTemplate, err = a.parseHelpTemplate("help", c.HelpTemplate)
+	if err != nil {
+		panic(fmt.Errorf("invalid help template: %v", err))
+	}
+	a.commandHelpTemplate, err = a.parseHelpTemplate("command help", c.CommandHelpTemplate)
+	if err != nil {
+		panic(fmt.Errorf("invalid command help template: %v", err))
+	}
 
 	// Register the builtin flags.
 	a.flags.Bool("h", "help", false, "display help")
 	a.flags.BoolL("nocolor", false, "disable color output")
+	a.flags.BoolL("norc", false, "do not run the rc file")
 
 	// Register the user flags, if present.
 	if c.Flags != nil {
@@ -128,11 +151,39 @@ func (a *App) Commands() *Commands {
 
 // PrintError prints the given error.
 func (a *App) PrintError(err error) {
+	a.fprintError(a, err)
+}
+
+// fprintError prints the error to w.
+func (a *App) fprintError(w io.Writer, err error) {
 	if a.config.NoColor {
-		a.Printf("error: %v\n", err)
+		fmt.Fprintf(w, "error: %v\n", err)
 	} else {
-		a.config.ErrorColor.Fprint(a, "error: ")
-		a.Printf("%v\n", err)
+		a.config.ErrorColor.Fprint(w, "error: ")
+		fmt.Fprintf(w, "%v\n", err)
+	}
+}
+
+// printWarning prints the warning message to the error output w.
+func (a *App) printWarning(w io.Writer, msg string) {
+	if a.config.NoColor {
+		fmt.Fprintf(w, "warning: %s\n", msg)
+	} else {
+		a.config.WarningColor.Fprint(w, "warning: ")
+		fmt.Fprintf(w, "%s\n", msg)
+	}
+}
+
+// warnDeprecatedFlags prints a warning for each deprecated flag,
+// which has been set explicitly.
+func (a *App) warnDeprecatedFlags(w io.Writer, flags *Flags, fm FlagMap) {
+	for _, fi := range flags.list {
+		if len(fi.deprecated) == 0 {
+			continue
+		}
+		if i, ok := fm[fi.Long]; ok && !i.IsDefault {
+			a.printWarning(w, fmt.Sprintf("flag '--%s' is deprecated, %s", fi.Long, fi.deprecated))
+		}
 	}
 }
 
@@ -199,6 +250,11 @@ func (a *App) Write(p []byte) (int, error) {
 // Stdout returns a writer to Stdout, using readline if available.
 // Note that calling before Run() will return a different instance.
 func (a *App) Stdout() io.Writer {
+	return a.recordWriter(a.terminalStdout())
+}
+
+// terminalStdout returns the output of readline, if available.
+func (a *App) terminalStdout() io.Writer {
 	if a.rl != nil {
 		return a.rl.Stdout()
 	}
@@ -209,9 +265,9 @@ func (a *App) Stdout() io.Writer {
 // Note that calling before Run() will return a different instance.
 func (a *App) Stderr() io.Writer {
 	if a.rl != nil {
-		return a.rl.Stderr()
+		return a.recordWriter(a.rl.Stderr())
 	}
-	return os.Stderr
+	return a.recordWriter(os.Stderr)
 }
 
 // AddCommand adds a new command.
@@ -237,39 +293,82 @@ func (a *App) addCommand(cmd *Command, addHelpFlag bool) {
 // RunCommand runs a single command.
 func (a *App) RunCommand(args []string) error {
 	// Parse the arguments string and obtain the command path to the root,
-	// and the command flags.
-	cmds, fg, args, err := a.commands.parse(args, a.flagMap, false)
+	// the command flags and the arguments following a double dash.
+	cmds, fg, args, dashArgs, err := a.commands.parse(args, a.flagMap, false)
 	if err != nil {
-		return err
+		return a.suggestFlag(err)
 	} else if len(cmds) == 0 {
-		return fmt.Errorf("unknown command, try 'help'")
+		return a.unknownCommandError(args, &a.commands, nil)
+	}
+
+	// Warn about the usage of deprecated commands and flags.
+	for _, c := range cmds {
+		if len(c.Deprecated) > 0 {
+			a.printWarning(a.Stderr(), fmt.Sprintf("command '%s' is deprecated, %s", c.Name, c.Deprecated))
+		}
+		a.warnDeprecatedFlags(a.Stderr(), &c.flags, fg)
 	}
 
 	// The last command is the final command.
 	cmd := cmds[len(cmds)-1]
 
-	// Print the command help if the command run function is nil or if the help flag is set.
-	if fg.Bool("help") || cmd.Run == nil {
+	// Print the command help if the help flag is set.
+	if fg.Bool("help") {
+		a.printCommandHelp(a, cmd, a.isShell)
+		return nil
+	}
+
+	// A command without run function only groups its sub commands.
+	// Any positional argument must be an unknown sub command then.
+	if cmd.Run == nil {
+		if len(args) > 0 && len(cmd.commands.list) > 0 {
+			return a.unknownCommandError(args, &cmd.commands, cmd)
+		}
 		a.printCommandHelp(a, cmd, a.isShell)
 		return nil
 	}
 
-	// Parse the arguments.
+	// Check the required flags. Missing ones are asked for within the shell, if enabled.
+	err = a.checkRequiredFlags(cmds, fg, a.canPrompt())
+	if err != nil {
+		return err
+	}
+
+	// Parse the arguments. The arguments following a double dash are
+	// passed as well, because they might be flag-like positional arguments.
 	cmdArgMap := make(ArgMap)
-	args, err = cmd.args.parse(args, cmdArgMap)
+	rest, err := a.parseArgs(cmd, append(args, dashArgs...), cmdArgMap, a.canPrompt())
 	if err != nil {
 		return err
 	}
 
 	// Check, if values from the argument string are not consumed (and therefore invalid).
-	if len(args) > 0 {
-		return fmt.Errorf("invalid usage of command '%s' (unconsumed input '%s'), try 'help'", cmd.Name, strings.Join(args, " "))
+	// Only the arguments following a double dash may be left over.
+	if len(rest) > len(dashArgs) {
+		unconsumed := strings.Join(rest[:len(rest)-len(dashArgs)], " ")
+		err = fmt.Errorf("invalid usage of command '%s' (unconsumed input '%s'), try 'help'", cmd.Name, unconsumed)
+
+		// If no argument was consumed, the first one might be a mistyped sub command.
+		if len(rest)-len(dashArgs) == len(args) {
+			if s := didYouMean(suggestions(args[0], cmd.commands.names(), a.config.SuggestionDistance)); len(s) > 0 {
+				err = fmt.Errorf("invalid usage of command '%s' (unconsumed input '%s'), %s", cmd.Name, unconsumed, s)
+			}
+		}
+		return &UsageError{Command: cmd.Name, Err: err}
 	}
 
 	// Create the context and pass the rest args.
 	ctx := newContext(a, cmd, fg, cmdArgMap)
+	ctx.RestArgs = rest
 
-	// Run the command.
+	// Ask for confirmation, if required by the command.
+	err = a.confirmCommand(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Run the command. Running progress bars are stopped afterwards.
+	defer ctx.stopProgress()
 	err = cmd.Run(ctx)
 	if err != nil {
 		return err
@@ -278,6 +377,35 @@ func (a *App) RunCommand(args []string) error {
 	return nil
 }
 
+// unknownCommandError returns the error for the unknown command args[0],
+// which is looked up in cmds. Parent is nil for top-level commands.
+// Similar command names are suggested.
+func (a *App) unknownCommandError(args []string, cmds *Commands, parent *Command) error {
+	err := &UnknownCommandError{}
+	if len(args) > 0 {
+		err.Name = args[0]
+	}
+	if parent != nil {
+		err.Parent = parent.Name
+	}
+	err.Suggestions = suggestions(err.Name, cmds.names(), a.config.SuggestionDistance)
+	return err
+}
+
+// suggestFlag adds suggestions for similar flags to an unknown flag error.
+// Other errors are returned unchanged.
+func (a *App) suggestFlag(err error) error {
+	var fe *FlagError
+	if !errors.As(err, &fe) || fe.flags == nil {
+		return err
+	}
+
+	if s := didYouMean(suggestions(fe.Flag, fe.flags.names(), a.config.SuggestionDistance)); len(s) > 0 {
+		fe.Err = fmt.Errorf("%w, %s", fe.Err, s)
+	}
+	return err
+}
+
 // Run the application and parse the command line arguments.
 // This method blocks.
 func (a *App) Run() (err error) {
@@ -295,6 +423,7 @@ func (a *App) RunWithReadline(rl *readline.Instance) (err error) {
 	defer a.Close()
 
 	a.setReadlineDefaults(rl.Config)
+	a.in = rl
 
 	// Sort all commands by their name.
 	a.commands.SortRecursive()
@@ -308,11 +437,18 @@ func (a *App) RunWithReadline(rl *readline.Instance) (err error) {
 	// Parse the app command line flags.
 	args, err = a.flags.parse(args, a.flagMap)
 	if err != nil {
-		return err
+		return a.suggestFlag(err)
 	}
 
-	// Check if nocolor was set.
-	a.config.NoColor = a.flagMap.Bool("nocolor")
+	// Check if colors are disabled by the config, the nocolor flag, the
+	// NO_COLOR environment variable or because the output is not a terminal.
+	// The default prompt is colored by New, but a custom prompt is kept.
+	defaultPrompt := a.config.prompt()
+	a.config.NoColor = a.config.NoColor || a.flagMap.Bool("nocolor") || a.colorDisabled()
+	if a.currentPrompt == defaultPrompt {
+		a.SetDefaultPrompt()
+	}
+	a.warnDeprecatedFlags(a.Stderr(), &a.flags, a.flagMap)
 
 	// Determine if this is a shell session.
 	a.isShell = len(args) == 0
@@ -334,7 +470,11 @@ func (a *App) RunWithReadline(rl *readline.Instance) (err error) {
 			if err != nil {
 				return err
 			} else if cmd == nil {
-				a.PrintError(fmt.Errorf("command not found"))
+				if s := didYouMean(suggestions(args[0], a.commands.names(), a.config.SuggestionDistance)); len(s) > 0 {
+					a.PrintError(fmt.Errorf("command not found, %s", s))
+				} else {
+					a.PrintError(fmt.Errorf("command not found"))
+				}
 				return nil
 			}
 			a.printCommandHelp(a, cmd, a.isShell)
@@ -342,6 +482,15 @@ func (a *App) RunWithReadline(rl *readline.Instance) (err error) {
 		},
 		isBuiltin: true,
 	}, false)
+	a.addCommand(&Command{
+		Name:   "__schema",
+		Help:   "print the command schema as JSON",
+		Hidden: true,
+		Run: func(c *Context) error {
+			return a.printSchema()
+		},
+		isBuiltin: true,
+	}, false)
 
 	// Check if help should be displayed.
 	if a.flagMap.Bool("help") {
@@ -371,6 +520,7 @@ func (a *App) RunWithReadline(rl *readline.Instance) (err error) {
 			},
 			isBuiltin: true,
 		})
+		a.addRecordCommand()
 	}
 
 	// Run the init hook.
@@ -389,9 +539,17 @@ func (a *App) RunWithReadline(rl *readline.Instance) (err error) {
 	// Assign readline instance
 	a.rl = rl
 	closer.Hook(a.Closer, func(h closer.H) {
-		h.OnCloseWithErr(a.rl.Close)
+		h.OnCloseWithErr(a.rl.Close, a.StopRecording)
 	})
 
+	// Record the session from the start.
+	if len(a.config.Transcript) > 0 {
+		err = a.StartRecording(a.config.Transcript, a.config.TranscriptFormat)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Run the shell hook.
 	if a.shellHook != nil {
 		err = a.shellHook(a)
@@ -405,6 +563,9 @@ func (a *App) RunWithReadline(rl *readline.Instance) (err error) {
 		a.printASCIILogo(a)
 	}
 
+	// Run the rc file.
+	a.runRCFile()
+
 	// Run the shell.
 	return a.runShell()
 }
@@ -415,8 +576,35 @@ func (a *App) setReadlineDefaults(config *readline.Config) {
 	config.DisableAutoSaveHistory = true
 	config.HistoryFile = a.config.HistoryFile
 	config.HistoryLimit = a.config.HistoryLimit
-	config.AutoComplete = newCompleter(&a.commands)
 	config.VimMode = a.config.VimMode
+
+	// The completer prints candidates, which readline can not list, to the
+	// app output and replaces the typed word with the listener.
+	cp := newCompleter(&a.commands)
+	cp.match = a.config.CompletionMatch
+	cp.out = a
+	cp.descriptions = a.config.CompletionDescriptions
+	cp.colorDesc = func(s string) string {
+		return a.colorize(a.config.CompletionColor, s)
+	}
+	config.AutoComplete = cp
+
+	// The listeners of the app are called before the one of the caller.
+	ls := listeners{cp}
+
+	var painter readline.Painter
+	if a.config.SyntaxHighlighting {
+		painter = highlighter{a: a}
+	}
+	if a.config.Autosuggestions {
+		a.autosuggester = newAutosuggester(a, painter)
+		painter = a.autosuggester
+		ls = append(ls, a.autosuggester)
+	}
+	if painter != nil {
+		config.Painter = painter
+	}
+	config.Listener = append(ls, callerListeners(config.Listener)...)
 }
 
 func (a *App) runShell() error {
@@ -475,18 +663,15 @@ Loop:
 			a.PrintError(err)
 			continue Loop
 		}
-
-		// Split the line to args.
-		args, err := shlex.Split(line, true)
-		if err != nil {
-			a.PrintError(fmt.Errorf("invalid args: %v", err))
-			continue Loop
+		if a.autosuggester != nil {
+			a.autosuggester.add(line)
 		}
 
-		// Execute the command.
-		err = a.RunCommand(args)
+		// Execute the command with the variables and aliases expanded.
+		a.recordInput(line)
+		err = a.runLine(line)
 		if err != nil {
-			a.PrintError(err)
+			a.printLineError(err)
 			// Do not continue the Loop here. We want to handle command changes below.
 		}
 
@@ -0,0 +1,70 @@
+package grumble
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// newTestApp creates an app with the given commands for tests.
+// The config is optional. The name defaults to test and the rc file
+// is disabled, unless set explicitly.
+func newTestApp(t *testing.T, c *Config, cmds ...*Command) *App {
+	t.Helper()
+
+	if c == nil {
+		c = &Config{}
+	}
+	if len(c.Name) == 0 {
+		c.Name = "test"
+	}
+	if len(c.RCFile) == 0 {
+		c.NoRCFile = true
+	}
+
+	a := New(c)
+	for _, cmd := range cmds {
+		a.AddCommand(cmd)
+	}
+	return a
+}
+
+// ---------------------------------------------------------------------------
+// TestAppNoColorPrompt
+// ---------------------------------------------------------------------------
+
+func TestAppNoColorPrompt(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	args := os.Args
+	os.Args = []string{"app"}
+	t.Cleanup(func() { os.Args = args })
+
+	cyan := color.New(color.FgCyan)
+	cyan.EnableColor()
+	a := newTestApp(t, &Config{Name: "app", PromptColor: cyan})
+	if !strings.Contains(a.currentPrompt, "\x1b[") {
+		t.Fatalf("expected a colored prompt before the run, got %q", a.currentPrompt)
+	}
+
+	// The shell exits at the end of the input.
+	newScriptedShell(t, a)
+	if err := a.RunWithReadline(a.rl); err != nil {
+		t.Fatal(err)
+	}
+	if a.currentPrompt != "app » " {
+		t.Errorf("unexpected prompt %q", a.currentPrompt)
+	}
+
+	// A custom prompt is kept.
+	a = newTestApp(t, &Config{Name: "app", PromptColor: cyan})
+	a.SetPrompt("custom> ")
+	newScriptedShell(t, a)
+	if err := a.RunWithReadline(a.rl); err != nil {
+		t.Fatal(err)
+	}
+	if a.currentPrompt != cyan.Sprint("custom> ") {
+		t.Errorf("unexpected custom prompt %q", a.currentPrompt)
+	}
+}
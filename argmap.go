@@ -26,6 +26,7 @@ package grumble
 
 import (
 	"fmt"
+	"sort"
 	"time"
 )
 
@@ -38,6 +39,29 @@ type ArgMapItem struct {
 // ArgMap holds all the parsed arg values.
 type ArgMap map[string]*ArgMapItem
 
+// Has returns true, if the arg is registered.
+func (a ArgMap) Has(name string) bool {
+	_, ok := a[name]
+	return ok
+}
+
+// IsSet returns true, if the arg has been passed explicitly
+// and does not hold its default value.
+func (a ArgMap) IsSet(name string) bool {
+	i, ok := a[name]
+	return ok && !i.IsDefault
+}
+
+// Names returns the sorted names of all args.
+func (a ArgMap) Names() []string {
+	names := make([]string, 0, len(a))
+	for k := range a {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // String returns the given arg value as string.
 // Panics if not present. Args must be registered.
 func (a ArgMap) String(name string) string {
@@ -286,3 +310,15 @@ func (a ArgMap) DurationList(long string) []time.Duration {
 	}
 	return v
 }
+
+func (a ArgMap) lookupValue(name string) (interface{}, bool) {
+	i, ok := a[name]
+	if !ok {
+		return nil, false
+	}
+	return i.Value, true
+}
+
+func (a ArgMap) kind() string {
+	return "arg"
+}
@@ -69,3 +69,34 @@ func Default(v interface{}) ArgOption {
 		i.optional = true
 	}
 }
+
+// ArgPrompt sets the options to ask for the argument value,
+// if it is missing and Config.PromptMissing is enabled.
+func ArgPrompt(po PromptOptions) ArgOption {
+	return func(i *argItem) {
+		i.prompt = &po
+	}
+}
+
+// ArgCompleter sets the completion function for the argument value.
+func ArgCompleter(f CompleteFunc) ArgOption {
+	if f == nil {
+		panic("nil completer not allowed")
+	}
+
+	return func(i *argItem) {
+		i.completer = f.candidates()
+	}
+}
+
+// ArgCandidates sets the completion function for the argument value,
+// which returns candidates with descriptions.
+func ArgCandidates(f CandidatesFunc) ArgOption {
+	if f == nil {
+		panic("nil completer not allowed")
+	}
+
+	return func(i *argItem) {
+		i.completer = f
+	}
+}
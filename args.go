@@ -46,6 +46,22 @@ type argItem struct {
 	optional bool
 	listMin  int
 	listMax  int
+
+	completer CandidatesFunc
+	path      *PathOptions // Set for path arguments.
+	pathKind  pathKind
+	prompt    *PromptOptions
+}
+
+// checkListRange returns an error, if the number of list elements is out of range.
+func (i *argItem) checkListRange(n int) error {
+	if n < i.listMin {
+		return &ArgError{Arg: i.Name, Err: fmt.Errorf("argument '%s' requires at least %d element(s)", i.Name, i.listMin)}
+	}
+	if i.listMax > 0 && n > i.listMax {
+		return &ArgError{Arg: i.Name, Err: fmt.Errorf("argument '%s' requires at most %d element(s)", i.Name, i.listMax)}
+	}
+	return nil
 }
 
 // Args holds all the registered args.
@@ -112,6 +128,16 @@ func (a *Args) register(
 	a.list = append(a.list, item)
 }
 
+// find returns the argument with the name or nil.
+func (a *Args) find(name string) *argItem {
+	for _, i := range a.list {
+		if i.Name == name {
+			return i
+		}
+	}
+	return nil
+}
+
 // empty returns true, if the args are empty.
 func (a *Args) empty() bool {
 	return len(a.list) == 0
@@ -126,11 +152,8 @@ func (a *Args) parse(args []string, res ArgMap) ([]string, error) {
 		// If it is a list argument, it will consume the rest of the input.
 		// Check that it matches its range.
 		if item.isList {
-			if len(args) < item.listMin {
-				return nil, fmt.Errorf("argument '%s' requires at least %d element(s)", item.Name, item.listMin)
-			}
-			if item.listMax > 0 && len(args) > item.listMax {
-				return nil, fmt.Errorf("argument '%s' requires at most %d element(s)", item.Name, item.listMax)
+			if err = item.checkListRange(len(args)); err != nil {
+				return nil, err
 			}
 		}
 
@@ -138,7 +161,7 @@ func (a *Args) parse(args []string, res ArgMap) ([]string, error) {
 		if len(args) == 0 {
 			// Check, if the argument is mandatory.
 			if !item.optional {
-				return nil, fmt.Errorf("missing argument '%s'", item.Name)
+				return nil, &ArgError{Arg: item.Name, Err: fmt.Errorf("%w '%s'", ErrMissingArgument, item.Name)}
 			}
 
 			// Register its default value.
@@ -148,13 +171,39 @@ func (a *Args) parse(args []string, res ArgMap) ([]string, error) {
 
 		args, err = item.parser(args, res)
 		if err != nil {
-			return nil, err
+			return nil, &ArgError{Arg: item.Name, Err: err}
 		}
 	}
 
 	return args, nil
 }
 
+// parsePartial parses the given args, which might still be incomplete,
+// and returns the argument item the next value belongs to.
+// Returns nil, if no further value is accepted.
+// Invalid values are skipped, because this is used for completion.
+func (a *Args) parsePartial(args []string, res ArgMap) *argItem {
+	for _, item := range a.list {
+		if item.isList {
+			if item.listMax > 0 && len(args) >= item.listMax {
+				return nil
+			}
+			if len(args) > 0 {
+				_, _ = item.parser(args, res)
+			}
+			return item
+		}
+
+		if len(args) == 0 {
+			return item
+		}
+		_, _ = item.parser(args, res)
+		args = args[1:]
+	}
+
+	return nil
+}
+
 // String registers a string argument.
 func (a *Args) String(name, help string, opts ...ArgOption) {
 	a.register(name, help, "string", false,
@@ -442,3 +491,36 @@ func (a *Args) DurationList(name, help string, opts ...ArgOption) {
 		opts...,
 	)
 }
+
+// File registers a file path argument, which is completed from the file system.
+// A leading tilde is expanded to the user's home directory.
+func (a *Args) File(name, help string, po PathOptions, opts ...ArgOption) {
+	a.registerPath(name, help, "file", pathFile, po, opts...)
+}
+
+// Dir registers a directory path argument, which is completed from the file system.
+// A leading tilde is expanded to the user's home directory.
+func (a *Args) Dir(name, help string, po PathOptions, opts ...ArgOption) {
+	a.registerPath(name, help, "dir", pathDir, po, opts...)
+}
+
+func (a *Args) registerPath(name, help, helpArgs string, kind pathKind, po PathOptions, opts ...ArgOption) {
+	// Prepend the path completer, so a custom completer option takes precedence.
+	opts = append([]ArgOption{
+		ArgCompleter(newPathCompleter(po, kind)),
+		func(i *argItem) { i.path, i.pathKind = &po, kind },
+	}, opts...)
+
+	a.register(name, help, helpArgs, false,
+		func(args []string, res ArgMap) ([]string, error) {
+			p, err := po.parse(args[0], kind)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value '%s' for argument %s: %w", helpArgs, args[0], name, err)
+			}
+
+			res[name] = &ArgMapItem{Value: p}
+			return args[1:], nil
+		},
+		opts...,
+	)
+}
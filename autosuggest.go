@@ -0,0 +1,198 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/desertbit/readline"
+)
+
+// autosuggester shows the latest history entry starting with the typed line
+// as ghost text after the cursor. It implements the readline.Painter and
+// readline.Listener interfaces. The suggestion is accepted with the right
+// arrow or end key, if the cursor is at the end of the line.
+type autosuggester struct {
+	a       *App
+	painter readline.Painter // Paints the typed line, optional.
+
+	mu      sync.Mutex
+	history []string // Oldest first.
+	prev    []rune   // The line at the end of the previous change.
+	prevEnd bool     // The cursor was at the end of the previous line.
+}
+
+// newAutosuggester creates an autosuggester with the entries of the history file.
+func newAutosuggester(a *App, painter readline.Painter) *autosuggester {
+	s := &autosuggester{a: a, painter: painter}
+	if len(a.config.HistoryFile) == 0 {
+		return s
+	}
+
+	f, err := os.Open(a.config.HistoryFile)
+	if err != nil {
+		return s
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		s.add(sc.Text())
+	}
+	return s
+}
+
+// add a line to the history. The history is limited like the readline history.
+func (s *autosuggester) add(line string) {
+	if len(strings.TrimSpace(line)) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = append(s.history, line)
+	if limit := s.a.config.HistoryLimit; limit > 0 && len(s.history) > limit {
+		s.history = s.history[len(s.history)-limit:]
+	}
+}
+
+// suggest returns the latest history entry starting with the line.
+// Entries, which are valid for the command tree, are preferred.
+func (s *autosuggester) suggest(line string) string {
+	if len(strings.TrimSpace(line)) == 0 {
+		return ""
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var fallback string
+	for i := len(s.history) - 1; i >= 0; i-- {
+		h := s.history[i]
+		if len(h) <= len(line) || !strings.HasPrefix(h, line) {
+			continue
+		} else if s.a.validLine(h) {
+			return h
+		} else if len(fallback) == 0 {
+			fallback = h
+		}
+	}
+	return fallback
+}
+
+// Paint the line and append the suggestion in the autosuggestion color.
+// The cursor is moved back to the end of the typed line.
+func (s *autosuggester) Paint(line []rune, pos int) []rune {
+	painted := line
+	if s.painter != nil {
+		painted = s.painter.Paint(line, pos)
+	}
+
+	// The line ends with a newline, if it has been submitted.
+	a := s.a
+	if a.config.NoColor || pos != len(line) || (len(line) > 0 && line[len(line)-1] == '\n') {
+		return painted
+	}
+
+	suggestion := s.suggest(string(line))
+	if len(suggestion) == 0 {
+		return painted
+	}
+
+	// The cursor can not be moved back across wrapped lines.
+	ghost := suggestion[len(string(line)):]
+	width := textWidth(ghost)
+	if textWidth(a.currentPrompt)+textWidth(string(line))+width >= a.outputWidth() {
+		return painted
+	}
+
+	ghost = a.config.AutosuggestionColor.Sprint(ghost) + strings.Repeat("\b", width)
+	return append(painted[:len(painted):len(painted)], []rune(ghost)...)
+}
+
+// OnChange accepts the suggestion, if the right arrow or end key is pressed
+// while the cursor is at the end of the line.
+func (s *autosuggester) OnChange(line []rune, pos int, key rune) (newLine []rune, newPos int, ok bool) {
+	accept := (key == readline.CharForward || key == readline.CharLineEnd) &&
+		s.prevEnd && string(s.prev) == string(line)
+	s.prev, s.prevEnd = append(s.prev[:0], line...), pos == len(line)
+
+	if !accept {
+		return nil, 0, false
+	}
+	suggestion := s.suggest(string(line))
+	if len(suggestion) == 0 {
+		return nil, 0, false
+	}
+
+	newLine = []rune(suggestion)
+	s.prev = append(s.prev[:0], newLine...)
+	return newLine, len(newLine), true
+}
+
+// validLine returns true, if the line runs a command of the command tree.
+func (a *App) validLine(line string) bool {
+	tokens := splitTokens([]rune(line))
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.word
+	}
+
+	cmds, _, _, _, err := a.commands.parse(words, nil, true)
+	return err == nil && len(cmds) > 0
+}
+
+// listeners calls all readline listeners in order. Each listener
+// receives the line changed by the previous ones.
+type listeners []readline.Listener
+
+func (l listeners) OnChange(line []rune, pos int, key rune) (newLine []rune, newPos int, ok bool) {
+	for _, li := range l {
+		if nl, np, changed := li.OnChange(line, pos, key); changed {
+			line, pos, ok = nl, np, true
+		}
+	}
+	return line, pos, ok
+}
+
+// callerListeners returns the listeners of the readline config,
+// which have not been set by the app.
+func callerListeners(l readline.Listener) listeners {
+	switch l := l.(type) {
+	case nil, *completer, *autosuggester:
+		return nil
+	case listeners:
+		var ls listeners
+		for _, li := range l {
+			ls = append(ls, callerListeners(li)...)
+		}
+		return ls
+	default:
+		return listeners{l}
+	}
+}
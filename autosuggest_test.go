@@ -0,0 +1,114 @@
+package grumble
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/desertbit/readline"
+	"github.com/fatih/color"
+)
+
+// writeHistory writes the lines to a history file and returns its path.
+func writeHistory(t *testing.T, lines ...string) string {
+	file := filepath.Join(t.TempDir(), "history")
+	err := os.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// ---------------------------------------------------------------------------
+// TestAutosuggestions
+// ---------------------------------------------------------------------------
+
+func TestAutosuggestions(t *testing.T) {
+	a := newTestApp(t, &Config{
+		HistoryFile:     writeHistory(t, "deploy api", "deploy web", "deplo typo", "status"),
+		Autosuggestions: true,
+	}, &Command{
+		Name: "deploy",
+		Help: "deploy an app",
+		Args: func(a *Args) {
+			a.String("app", "the app")
+		},
+		Run: func(c *Context) error { return nil },
+	})
+	newScriptedShell(t, a)
+	s := a.autosuggester
+
+	tests := []struct {
+		line string
+		want string
+	}{
+		{line: "", want: ""},
+		{line: "dep", want: "deploy web"},
+		{line: "deploy a", want: "deploy api"},
+		{line: "deplo t", want: "deplo typo"},
+		{line: "status", want: ""},
+		{line: "x", want: ""},
+	}
+	for _, tt := range tests {
+		if got := s.suggest(tt.line); got != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.line, tt.want, got)
+		}
+	}
+
+	// New lines are suggested first.
+	s.add("deploy db")
+	if got := s.suggest("dep"); got != "deploy db" {
+		t.Errorf("expected the latest entry, got %q", got)
+	}
+}
+
+func TestAutosuggestionsPaint(t *testing.T) {
+	gray := color.New(color.FgHiBlack)
+	gray.EnableColor()
+	a := newTestApp(t, &Config{
+		HistoryFile:         writeHistory(t, "deploy web"),
+		Autosuggestions:     true,
+		AutosuggestionColor: gray,
+	})
+	newScriptedShell(t, a)
+	s := a.autosuggester
+	ghost := gray.Sprint("loy web") + strings.Repeat("\b", 7)
+
+	if got := string(s.Paint([]rune("dep"), 3)); got != "dep"+ghost {
+		t.Errorf("unexpected painted line %q", got)
+	}
+	if got := string(s.Paint([]rune("dep"), 2)); got != "dep" {
+		t.Errorf("expected no suggestion within the line, got %q", got)
+	}
+	if got := string(s.Paint([]rune("dep\n"), 4)); got != "dep\n" {
+		t.Errorf("expected no suggestion for the submitted line, got %q", got)
+	}
+
+	a.config.NoColor = true
+	if got := string(s.Paint([]rune("dep"), 3)); got != "dep" {
+		t.Errorf("expected no suggestion without colors, got %q", got)
+	}
+}
+
+func TestAutosuggestionsAccept(t *testing.T) {
+	a := newTestApp(t, &Config{
+		HistoryFile:     writeHistory(t, "deploy web"),
+		Autosuggestions: true,
+	})
+	newScriptedShell(t, a)
+	l := a.rl.Config.Listener
+
+	// Moving the cursor to the end does not accept the suggestion.
+	if _, _, ok := l.OnChange([]rune("dep"), 2, 'p'); ok {
+		t.Fatal("unexpected change")
+	}
+	if _, _, ok := l.OnChange([]rune("dep"), 3, readline.CharForward); ok {
+		t.Fatal("expected no change, if the cursor was not at the end")
+	}
+
+	line, pos, ok := l.OnChange([]rune("dep"), 3, readline.CharLineEnd)
+	if !ok || string(line) != "deploy web" || pos != 10 {
+		t.Errorf("expected the accepted suggestion, got %q %d %v", string(line), pos, ok)
+	}
+}
@@ -26,6 +26,7 @@ package grumble
 
 import (
 	"fmt"
+	"text/template"
 )
 
 // Command is just that, a command for your application.
@@ -44,6 +45,14 @@ type Command struct {
 	// More descriptive help message for the command.
 	LongHelp string
 
+	// Hidden hides the command from the help and completion.
+	Hidden bool
+
+	// Deprecated marks the command as deprecated. The message is printed
+	// with a warning if the command is used and should name the replacement.
+	// Deprecated commands are hidden from the help and completion.
+	Deprecated string
+
 	// HelpGroup defines the help group headline.
 	// Note: this is only used for primary top-level commands.
 	HelpGroup string
@@ -52,6 +61,19 @@ type Command struct {
 	// Sample: start [OPTIONS] CONTAINER [CONTAINER...]
 	Usage string
 
+	// Examples of the command usage, printed verbatim in the help.
+	Examples string
+
+	// SeeAlso lists related commands by their path, e.g. "admin users".
+	SeeAlso []string
+
+	// Confirm asks for confirmation with the message before the command is run.
+	// The message is a text/template executed with the *Context, for example
+	// "delete pod '{{.Args.String "pod"}}'?". A --yes/-y flag is added to skip it,
+	// unless the command defines a --yes flag itself. The short -y is only
+	// added, if the command does not define it.
+	Confirm string
+
 	// Define all command flags within this function.
 	Flags func(f *Flags)
 
@@ -65,9 +87,14 @@ type Command struct {
 	// It takes in command arguments and returns autocomplete options.
 	// By default all commands get autocomplete of subcommands.
 	// A non-nil Completer overrides the default behaviour.
+	// Its results are filtered by the configured match mode.
+	// Use the ArgCompleter and FlagCompleter options to complete
+	// single argument and flag values instead.
 	Completer func(prefix string, args []string) []string
 
 	parent    *Command
+	confirm   *template.Template
+	yesFlag   bool // Whenever the --yes flag skipping the confirmation has been added.
 	flags     Flags
 	args      Args
 	commands  Commands
@@ -91,14 +118,45 @@ func (c *Command) registerFlagsAndArgs(addHelpFlag bool) {
 		c.flags.Bool("h", "help", false, "display help")
 	}
 
+	if len(c.Confirm) > 0 {
+		t, err := template.New(c.Name).Parse(c.Confirm)
+		if err != nil {
+			panic(fmt.Errorf("invalid confirm message of command '%s': %v", c.Name, err))
+		}
+		c.confirm = t
+	}
+
 	if c.Flags != nil {
 		c.Flags(&c.flags)
 	}
+
+	// Add the flag skipping the confirmation, if its long name is not taken.
+	// The short name is skipped, if the command uses it already.
+	if c.confirm != nil {
+		c.yesFlag = true
+		short := "y"
+		for _, fi := range c.flags.list {
+			if fi.Long == "yes" {
+				c.yesFlag = false
+				break
+			} else if fi.Short == "y" {
+				short = ""
+			}
+		}
+		if c.yesFlag {
+			c.flags.Bool(short, "yes", false, "skip the confirmation")
+		}
+	}
 	if c.Args != nil {
 		c.Args(&c.args)
 	}
 }
 
+// hidden returns true, if the command should not be listed.
+func (c *Command) hidden() bool {
+	return c.Hidden || len(c.Deprecated) > 0
+}
+
 // Parent returns the parent command or nil.
 func (c *Command) Parent() *Command {
 	return c.parent
@@ -1,6 +1,8 @@
 package grumble
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -318,3 +320,85 @@ func TestParentNilByDefault(t *testing.T) {
 		t.Fatal("expected Parent() to be nil for a standalone command")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// TestCommandHiddenAndDeprecated
+// ---------------------------------------------------------------------------
+
+func TestCommandHiddenAndDeprecated(t *testing.T) {
+	var ran []string
+	run := func(c *Context) error {
+		ran = append(ran, c.Command.Name)
+		return nil
+	}
+
+	a := newTestApp(t, &Config{Name: "app", NoColor: true, HelpWidth: -1},
+		&Command{Name: "status", Help: "show status", Run: run,
+			Flags: func(f *Flags) {
+				f.BoolL("all", false, "show all")
+				f.BoolL("debug", false, "debug output", FlagHidden())
+				f.BoolL("everything", false, "show everything", FlagDeprecated("use --all instead"))
+			},
+		},
+		&Command{Name: "secret", Help: "secret command", Hidden: true, Run: run},
+		&Command{Name: "stat", Help: "old status", Deprecated: "use 'status' instead", Run: run},
+	)
+
+	// Hidden and deprecated commands and flags still parse.
+	var stderr string
+	stdout := captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			for _, args := range [][]string{
+				{"secret"},
+				{"stat"},
+				{"status", "--debug", "--everything"},
+			} {
+				if err := a.RunCommand(args); err != nil {
+					t.Errorf("%v: unexpected error: %v", args, err)
+				}
+			}
+		})
+	})
+	if !reflect.DeepEqual(ran, []string{"secret", "stat", "status"}) {
+		t.Errorf("unexpected commands run: %v", ran)
+	}
+	if stdout != "" {
+		t.Errorf("unexpected output: %q", stdout)
+	}
+
+	// Only deprecated ones print a warning.
+	want := "warning: command 'stat' is deprecated, use 'status' instead\n" +
+		"warning: flag '--everything' is deprecated, use --all instead\n"
+	if stderr != want {
+		t.Errorf("unexpected warnings:\n%q\nwant:\n%q", stderr, want)
+	}
+
+	// Both are hidden from the help.
+	help := captureStdout(t, func() { a.printHelp(a, true) })
+	if strings.Contains(help, "secret") || strings.Contains(help, "stat ") || !strings.Contains(help, "status") {
+		t.Errorf("unexpected app help:\n%s", help)
+	}
+	help = captureStdout(t, func() { a.printCommandHelp(a, a.commands.Get("status"), true) })
+	if strings.Contains(help, "debug") || strings.Contains(help, "everything") || !strings.Contains(help, "--all") {
+		t.Errorf("unexpected command help:\n%s", help)
+	}
+	help = captureStdout(t, func() { a.printCommandHelp(a, a.commands.Get("stat"), true) })
+	if !strings.Contains(help, "Deprecated: use 'status' instead") {
+		t.Errorf("missing deprecation in command help:\n%s", help)
+	}
+
+	// And from the completion.
+	c := newCompleter(&a.commands)
+	if got := complete(c, "s"); !reflect.DeepEqual(got, []string{"tatus "}) {
+		t.Errorf("unexpected command completion %q", got)
+	}
+	if got := complete(c, "status --"); !reflect.DeepEqual(got, []string{"all ", "help "}) {
+		t.Errorf("unexpected flag completion %q", got)
+	}
+
+	// And from suggestions.
+	err := a.RunCommand([]string{"secre"})
+	if err == nil || strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("unexpected suggestion: %v", err)
+	}
+}
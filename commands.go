@@ -26,21 +26,34 @@ package grumble
 
 import (
 	"sort"
+	"strings"
 )
 
 // Commands collection.
 type Commands struct {
 	list    []*Command
 	changed bool // Used to resort if something changes.
+	abbrev  bool // Whenever unique prefixes of command names are accepted.
 }
 
 // Add the command to the slice.
 // Duplicates are ignored.
 func (c *Commands) Add(cmd *Command) {
+	cmd.commands.SetAbbreviations(c.abbrev)
 	c.list = append(c.list, cmd)
 	c.changed = true
 }
 
+// SetAbbreviations enables or disables command abbreviations for all
+// commands and sub commands. If enabled, any unique prefix of a command
+// name or alias selects the command.
+func (c *Commands) SetAbbreviations(enabled bool) {
+	c.abbrev = enabled
+	for _, cmd := range c.list {
+		cmd.commands.SetAbbreviations(enabled)
+	}
+}
+
 // Remove a command from the slice.
 func (c *Commands) Remove(name string) (found bool) {
 	for index, cmd := range c.list {
@@ -75,30 +88,112 @@ func (c *Commands) All() []*Command {
 }
 
 // Get the command by the name. Aliases are also checked.
-// Returns nil if not found.
+// If abbreviations are enabled, a unique prefix is also accepted.
+// Returns nil if not found or ambiguous.
 func (c *Commands) Get(name string) *Command {
+	cmd, _ := c.Lookup(name)
+	return cmd
+}
+
+// Lookup the command by the name like Get. Returns an *AmbiguousCommandError,
+// if abbreviations are enabled and the name is a prefix of multiple commands.
+// Returns a nil command and error if not found.
+func (c *Commands) Lookup(name string) (*Command, error) {
+	// An exact match always wins.
 	for _, cmd := range c.list {
 		if cmd.Name == name {
-			return cmd
+			return cmd, nil
 		}
 		for _, a := range cmd.Aliases {
 			if a == name {
-				return cmd
+				return cmd, nil
 			}
 		}
 	}
-	return nil
+	if !c.abbrev || len(name) == 0 {
+		return nil, nil
+	}
+
+	// Hidden commands must be named in full.
+	var matches []*Command
+	for _, cmd := range c.list {
+		if !cmd.hidden() && hasPrefix(cmd, name) {
+			matches = append(matches, cmd)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	} else if len(matches) == 0 {
+		return nil, nil
+	}
+
+	err := &AmbiguousCommandError{Name: name}
+	for _, cmd := range matches {
+		err.Candidates = append(err.Candidates, cmd.Name)
+	}
+	sort.Strings(err.Candidates)
+	return nil, err
+}
+
+// Abbreviation returns the shortest unique prefix of the command name.
+// Returns the full name, if abbreviations are disabled or the
+// command is not part of the collection.
+func (c *Commands) Abbreviation(cmd *Command) string {
+	if !c.abbrev {
+		return cmd.Name
+	}
+	// The prefixes end on rune boundaries to keep them valid UTF-8.
+	for i := range cmd.Name {
+		if i == 0 {
+			continue
+		}
+		if found, _ := c.Lookup(cmd.Name[:i]); found == cmd {
+			return cmd.Name[:i]
+		}
+	}
+	return cmd.Name
+}
+
+// hasPrefix returns true, if the command name or an alias starts with the prefix.
+func hasPrefix(cmd *Command, prefix string) bool {
+	if strings.HasPrefix(cmd.Name, prefix) {
+		return true
+	}
+	for _, a := range cmd.Aliases {
+		if strings.HasPrefix(a, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// names returns the names and aliases of all visible commands.
+func (c *Commands) names() (names []string) {
+	for _, cmd := range c.list {
+		if cmd.hidden() {
+			continue
+		}
+		names = append(names, cmd.Name)
+		names = append(names, cmd.Aliases...)
+	}
+	return
 }
 
 // FindCommand searches for the final command through all children.
 // Returns a slice of non processed following command args.
 // Returns cmd=nil if not found.
 func (c *Commands) FindCommand(args []string) (cmd *Command, rest []string, err error) {
-	var cmds []*Command
-	cmds, _, rest, err = c.parse(args, nil, true)
+	var (
+		cmds     []*Command
+		dashRest []string
+	)
+	cmds, _, rest, dashRest, err = c.parse(args, nil, true)
 	if err != nil {
 		return
 	}
+	if dashRest != nil {
+		rest = append(append(rest, "--"), dashRest...)
+	}
 
 	if len(cmds) > 0 {
 		cmd = cmds[len(cmds)-1]
@@ -143,6 +238,9 @@ func (c *Commands) unsetChanged() {
 
 // parse the args and return a command path to the root.
 // cmds slice is empty, if no command was found.
+// Flags of the final command may be interleaved with its positional arguments,
+// which are returned as args. All arguments following a double dash (--) are
+// returned verbatim as rest. Rest is nil, if no double dash was passed.
 func (c *Commands) parse(
 	args []string,
 	parentFlagMap FlagMap,
@@ -151,18 +249,25 @@ func (c *Commands) parse(
 	cmds []*Command,
 	flagsMap FlagMap,
 	rest []string,
+	dashRest []string,
 	err error,
 ) {
-	var fgs []FlagMap
-	cur := c
+	var (
+		fg  FlagMap
+		fgs []FlagMap
+		cur = c
+	)
 
 	for len(args) > 0 && cur != nil {
 		// Extract the command name from the arguments.
 		name := args[0]
 
 		// Try to find the command.
-		cmd := cur.Get(name)
-		if cmd == nil {
+		var cmd *Command
+		cmd, err = cur.Lookup(name)
+		if err != nil {
+			return
+		} else if cmd == nil {
 			break
 		}
 
@@ -170,15 +275,23 @@ func (c *Commands) parse(
 		cmds = append(cmds, cmd)
 		cur = &cmd.commands
 
-		// Parse the command flags.
-		fg := make(FlagMap)
-		args, err = cmd.flags.parse(args, fg)
+		// Parse the command flags up to the next positional argument,
+		// which might be the name of a sub command.
+		fg = make(FlagMap)
+		args, err = cmd.flags.parseLeading(args, fg)
 		if err != nil {
 			return
 		}
 
-		if !skipFlagMaps {
-			fgs = append(fgs, fg)
+		fgs = append(fgs, fg)
+	}
+
+	// The flags of the final command are allowed anywhere between its
+	// positional arguments.
+	if len(cmds) > 0 {
+		args, dashRest, err = cmds[len(cmds)-1].flags.parseInterleaved(args, fg)
+		if err != nil {
+			return
 		}
 	}
 
@@ -0,0 +1,284 @@
+package grumble
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// newTestCommands creates a small command tree for parse tests:
+//
+//	deploy [--force] [--replicas n]
+//	admin [--verbose]
+//	  users [--all]
+func newTestCommands() *Commands {
+	var c Commands
+
+	deploy := &Command{
+		Name: "deploy",
+		Help: "deploy an app",
+		Flags: func(f *Flags) {
+			f.Bool("f", "force", false, "force it")
+			f.Int("r", "replicas", 1, "number of replicas")
+		},
+	}
+	deploy.registerFlagsAndArgs(true)
+	c.Add(deploy)
+
+	admin := &Command{
+		Name: "admin",
+		Help: "admin tools",
+		Flags: func(f *Flags) {
+			f.Bool("v", "verbose", false, "verbose output")
+		},
+	}
+	admin.registerFlagsAndArgs(true)
+	admin.AddCommand(&Command{
+		Name: "users",
+		Help: "list users",
+		Flags: func(f *Flags) {
+			f.BoolL("all", false, "list all users")
+		},
+	})
+	c.Add(admin)
+
+	return &c
+}
+
+// ---------------------------------------------------------------------------
+// TestCommandsParseInterleaved
+// ---------------------------------------------------------------------------
+
+func TestCommandsParseInterleaved(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCmds []string
+		wantRest []string
+		wantDash []string
+		wantErr  bool
+		check    func(t *testing.T, fg FlagMap)
+	}{
+		{
+			name:     "flags before positional",
+			args:     []string{"deploy", "--force", "myapp"},
+			wantCmds: []string{"deploy"},
+			wantRest: []string{"myapp"},
+			check: func(t *testing.T, fg FlagMap) {
+				if !fg.Bool("force") {
+					t.Fatal("expected force=true")
+				}
+			},
+		},
+		{
+			name:     "flags after positional",
+			args:     []string{"deploy", "myapp", "--force", "-r", "3"},
+			wantCmds: []string{"deploy"},
+			wantRest: []string{"myapp"},
+			check: func(t *testing.T, fg FlagMap) {
+				if !fg.Bool("force") {
+					t.Fatal("expected force=true")
+				}
+				if fg.Int("replicas") != 3 {
+					t.Fatalf("expected replicas=3, got %d", fg.Int("replicas"))
+				}
+			},
+		},
+		{
+			name:     "double dash rest",
+			args:     []string{"deploy", "myapp", "--", "--force", "x"},
+			wantCmds: []string{"deploy"},
+			wantRest: []string{"myapp"},
+			wantDash: []string{"--force", "x"},
+			check: func(t *testing.T, fg FlagMap) {
+				if fg.Bool("force") {
+					t.Fatal("expected force=false")
+				}
+			},
+		},
+		{
+			name:     "sub command with parent and child flags",
+			args:     []string{"admin", "-v", "users", "x", "--all"},
+			wantCmds: []string{"admin", "users"},
+			wantRest: []string{"x"},
+			check: func(t *testing.T, fg FlagMap) {
+				if !fg.Bool("verbose") || !fg.Bool("all") {
+					t.Fatal("expected verbose and all to be true")
+				}
+			},
+		},
+		{
+			name:     "double dash prevents sub command lookup",
+			args:     []string{"admin", "--", "users"},
+			wantCmds: []string{"admin"},
+			wantDash: []string{"users"},
+		},
+		{
+			name:    "parent flag after sub command",
+			args:    []string{"admin", "users", "--verbose"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid flag after positional",
+			args:    []string{"deploy", "myapp", "--unknown"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmds, fg, rest, dash, err := newTestCommands().parse(tt.args, nil, false)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var names []string
+			for _, c := range cmds {
+				names = append(names, c.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantCmds) {
+				t.Fatalf("expected commands %v, got %v", tt.wantCmds, names)
+			}
+			if len(rest) != 0 || len(tt.wantRest) != 0 {
+				if !reflect.DeepEqual(rest, tt.wantRest) {
+					t.Fatalf("expected rest %v, got %v", tt.wantRest, rest)
+				}
+			}
+			if len(dash) != 0 || len(tt.wantDash) != 0 {
+				if !reflect.DeepEqual(dash, tt.wantDash) {
+					t.Fatalf("expected dash rest %v, got %v", tt.wantDash, dash)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, fg)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCommandsFindCommand
+// ---------------------------------------------------------------------------
+
+func TestCommandsFindCommand(t *testing.T) {
+	cmd, rest, err := newTestCommands().FindCommand([]string{"deploy", "myapp", "--force", "--", "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmd == nil || cmd.Name != "deploy" {
+		t.Fatalf("expected command 'deploy', got %v", cmd)
+	}
+	if !reflect.DeepEqual(rest, []string{"myapp", "--", "x"}) {
+		t.Fatalf("expected [myapp -- x], got %v", rest)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCommandsAbbreviations
+// ---------------------------------------------------------------------------
+
+func newTestAbbrevCommands() *Commands {
+	var c Commands
+
+	show := &Command{Name: "show", Help: "show things"}
+	show.AddCommand(&Command{Name: "interfaces", Help: "show interfaces"})
+	show.AddCommand(&Command{Name: "ip", Help: "show ip"})
+	c.Add(show)
+	c.Add(&Command{Name: "shutdown", Help: "shutdown"})
+	c.Add(&Command{Name: "list", Aliases: []string{"ls"}, Help: "list things"})
+	c.Add(&Command{Name: "lsblk", Help: "list block devices"})
+
+	// Enabled after the tree is built to check the propagation.
+	c.SetAbbreviations(true)
+	return &c
+}
+
+func TestCommandsAbbreviations(t *testing.T) {
+	c := newTestAbbrevCommands()
+
+	tests := []struct {
+		args     []string
+		wantCmds []string
+		wantErr  []string
+	}{
+		{args: []string{"show", "interfaces"}, wantCmds: []string{"show", "interfaces"}},
+		{args: []string{"sho", "int"}, wantCmds: []string{"show", "interfaces"}},
+		{args: []string{"shu"}, wantCmds: []string{"shutdown"}},
+		{args: []string{"li"}, wantCmds: []string{"list"}},
+		{args: []string{"ls"}, wantCmds: []string{"list"}},
+		{args: []string{"lsb"}, wantCmds: []string{"lsblk"}},
+		{args: []string{"sh"}, wantErr: []string{"show", "shutdown"}},
+		{args: []string{"l"}, wantErr: []string{"list", "lsblk"}},
+		{args: []string{"show", "i"}, wantErr: []string{"interfaces", "ip"}},
+	}
+
+	for _, tt := range tests {
+		cmds, _, _, _, err := c.parse(tt.args, nil, true)
+		if tt.wantErr != nil {
+			var ae *AmbiguousCommandError
+			if !errors.As(err, &ae) {
+				t.Errorf("%v: expected ambiguous command error, got %v", tt.args, err)
+			} else if !reflect.DeepEqual(ae.Candidates, tt.wantErr) {
+				t.Errorf("%v: expected candidates %v, got %v", tt.args, tt.wantErr, ae.Candidates)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.args, err)
+			continue
+		}
+
+		var names []string
+		for _, cmd := range cmds {
+			names = append(names, cmd.Name)
+		}
+		if !reflect.DeepEqual(names, tt.wantCmds) {
+			t.Errorf("%v: expected commands %v, got %v", tt.args, tt.wantCmds, names)
+		}
+	}
+
+	// Sub commands added later inherit the setting.
+	c.Get("show").AddCommand(&Command{Name: "version", Help: "show version"})
+	if cmd := c.Get("show").commands.Get("v"); cmd == nil || cmd.Name != "version" {
+		t.Errorf("expected abbreviated sub command 'version', got %v", cmd)
+	}
+
+	// Abbreviations are disabled by default.
+	var d Commands
+	d.Add(&Command{Name: "show", Help: "show things"})
+	if d.Get("sh") != nil {
+		t.Error("expected no abbreviation by default")
+	}
+}
+
+func TestCommandsAbbreviation(t *testing.T) {
+	c := newTestAbbrevCommands()
+
+	tests := map[string]string{
+		"show":     "sho",
+		"shutdown": "shu",
+		"list":     "li",
+		"lsblk":    "lsb",
+	}
+	for name, want := range tests {
+		if got := c.Abbreviation(c.Get(name)); got != want {
+			t.Errorf("%s: expected abbreviation %q, got %q", name, want, got)
+		}
+	}
+
+	show := c.Get("show")
+	if got := show.commands.Abbreviation(show.commands.Get("interfaces")); got != "in" {
+		t.Errorf("expected abbreviation 'in', got %q", got)
+	}
+
+	// Multi-byte runes are not split.
+	c.Add(&Command{Name: "übersicht", Help: "overview"})
+	if got := c.Abbreviation(c.Get("übersicht")); got != "ü" {
+		t.Errorf("expected abbreviation 'ü', got %q", got)
+	}
+}
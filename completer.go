@@ -25,13 +25,73 @@
 package grumble
 
 import (
+	"fmt"
+	"io"
 	"strings"
+	"unicode"
 
+	"github.com/desertbit/columnize"
 	shlex "github.com/desertbit/go-shlex"
+	"github.com/desertbit/readline"
 )
 
+// CompleteFunc returns the completion candidates for the given prefix.
+// The flags and args contain the values, which have been parsed so far.
+// Candidates not matching the prefix are filtered out.
+type CompleteFunc func(prefix string, flags FlagMap, args ArgMap) []string
+
+// CandidatesFunc is the same as CompleteFunc, but returns candidates
+// with a description and a group.
+type CandidatesFunc func(prefix string, flags FlagMap, args ArgMap) []Candidate
+
+// Candidate is a completion candidate.
+type Candidate struct {
+	// Value is completed on the input line.
+	Value string
+
+	// Description is an optional one liner describing the value.
+	Description string
+
+	// Group is an optional headline to group candidates.
+	Group string
+}
+
+// candidates converts the complete function to a candidates function.
+func (f CompleteFunc) candidates() CandidatesFunc {
+	return func(prefix string, flags FlagMap, args ArgMap) []Candidate {
+		values := f(prefix, flags, args)
+		cands := make([]Candidate, len(values))
+		for i, v := range values {
+			cands[i] = Candidate{Value: v}
+		}
+		return cands
+	}
+}
+
 type completer struct {
 	commands *Commands
+	match    MatchMode
+
+	// out is the writer to print the candidates to, if readline can not list
+	// them. If nil, candidates requiring a replacement of the typed word are
+	// only completed, if they are unique.
+	out io.Writer
+
+	// descriptions prints the candidates with their descriptions to out
+	// instead of the plain readline candidate list.
+	descriptions bool
+
+	// colorDesc colors the descriptions, optional.
+	colorDesc func(s string) string
+
+	// replacement of the typed word, which is applied by the listener
+	// after readline handled the completion.
+	replacement *replacement
+}
+
+type replacement struct {
+	start int
+	value []rune
 }
 
 func newCompleter(commands *Commands) *completer {
@@ -40,106 +100,352 @@ func newCompleter(commands *Commands) *completer {
 	}
 }
 
+// OnChange implements the readline.Listener interface and replaces the
+// typed word, if the previous completion requested it.
+func (c *completer) OnChange(line []rune, pos int, key rune) (newLine []rune, newPos int, ok bool) {
+	r := c.replacement
+	c.replacement = nil
+	if r == nil || key != readline.CharTab || r.start > pos || pos > len(line) {
+		return nil, 0, false
+	}
+
+	newLine = append(newLine, line[:r.start]...)
+	newLine = append(newLine, r.value...)
+	newLine = append(newLine, line[pos:]...)
+	return newLine, r.start + len(r.value), true
+}
+
 func (c *completer) Do(line []rune, pos int) (newLine [][]rune, length int) {
 	// Discard anything after the cursor position.
 	// This is similar behaviour to shell/bash.
 	line = line[:pos]
 
-	var words []string
+	// The line might end within an open quote, which is closed by the completion.
+	var (
+		words []string
+		quote rune
+	)
 	if w, err := shlex.Split(string(line), true); err == nil {
 		words = w
-	} else {
+	} else if words, quote = splitOpenQuote(string(line)); words == nil {
 		words = strings.Fields(string(line)) // fallback
 	}
 
-	prefix := ""
-	if len(words) > 0 && pos >= 1 && line[pos-1] != ' ' {
+	prefix, start := "", pos
+	if len(words) > 0 && pos >= 1 && (quote != 0 || line[pos-1] != ' ') {
 		prefix = words[len(words)-1]
 		words = words[:len(words)-1]
+		start = lastWordStart(line)
 	}
 
 	// Simple hack to allow auto completion for help.
-	if len(words) > 0 && words[0] == "help" {
+	isHelp := len(words) > 0 && words[0] == "help"
+	if isHelp {
 		words = words[1:]
 	}
 
-	var (
-		cmds        *Commands
-		flags       *Flags
-		suggestions [][]rune
-	)
-
-	// Find the last commands list.
+	// Complete the top-level commands.
 	if len(words) == 0 {
-		cmds = c.commands
-	} else {
-		cmd, rest, err := c.commands.FindCommand(words)
-		if err != nil || cmd == nil {
+		return c.suggest(prefix, quote, start, commandCandidates(c.commands, prefix))
+	}
+
+	// Parse the words to find the command and the flags and args given so far.
+	// If this fails, the last word might be a flag still waiting for its value.
+	var valueFlag *flagItem
+	cmds, fg, args, dashArgs, err := c.commands.parse(words, nil, false)
+	if err != nil {
+		cmds, fg, args, dashArgs, err = c.commands.parse(words[:len(words)-1], nil, false)
+		if err != nil || len(cmds) == 0 || dashArgs != nil {
 			return
 		}
 
-		// Call the custom completer if present.
-		if cmd.Completer != nil {
-			words = cmd.Completer(prefix, rest)
-			for _, w := range words {
-				suggestions = append(suggestions, []rune(strings.TrimPrefix(w, prefix)))
+		valueFlag = cmds[len(cmds)-1].flags.find(words[len(words)-1])
+		if valueFlag == nil || valueFlag.allowEmptyValue {
+			return
+		}
+	} else if len(cmds) == 0 {
+		return
+	}
+	cmd := cmds[len(cmds)-1]
+
+	// Check, if the prefix is a flag with a joined value (--flag=value).
+	if valueFlag == nil && dashArgs == nil && strings.HasPrefix(prefix, "-") {
+		if i := strings.Index(prefix, "="); i > 0 {
+			valueFlag = cmd.flags.find(prefix[:i])
+			if valueFlag == nil {
+				return
 			}
-			return suggestions, len(prefix)
+			start += len([]rune(prefix[:i+1]))
+			prefix = prefix[i+1:]
 		}
+	}
 
-		// No rest must be there.
-		if len(rest) != 0 {
+	// Complete the flag value.
+	if valueFlag != nil {
+		if valueFlag.completer == nil {
 			return
 		}
+		argMap := make(ArgMap)
+		cmd.args.parsePartial(args, argMap)
+		return c.suggest(prefix, quote, start, valueFlag.completer(prefix, fg, argMap))
+	}
 
-		cmds = &cmd.commands
-		flags = &cmd.flags
+	// Call the custom completer if present.
+	if cmd.Completer != nil {
+		rest := args
+		if dashArgs != nil {
+			rest = append(append(rest, "--"), dashArgs...)
+		}
+
+		var candidates []Candidate
+		for _, w := range cmd.Completer(prefix, rest) {
+			candidates = append(candidates, Candidate{Value: w})
+		}
+		return c.suggest(prefix, quote, start, candidates)
 	}
 
-	if len(prefix) > 0 {
-		for _, cmd := range cmds.list {
-			if strings.HasPrefix(cmd.Name, prefix) {
-				suggestions = append(suggestions, []rune(strings.TrimPrefix(cmd.Name, prefix)))
-			}
+	// Complete the flag names. Flags are allowed anywhere before a double dash.
+	if dashArgs == nil && strings.HasPrefix(prefix, "-") {
+		return c.suggest(prefix, quote, start, flagNameCandidates(&cmd.flags))
+	}
+
+	// Complete the value of the next positional argument.
+	var candidates []Candidate
+	if !isHelp {
+		argMap := make(ArgMap)
+		ai := cmd.args.parsePartial(append(args, dashArgs...), argMap)
+		if ai != nil && ai.completer != nil {
+			candidates = ai.completer(prefix, fg, argMap)
+		}
+	}
+
+	// Sub commands are only valid before any positional argument.
+	// Flags are only listed without prefix, if no argument values are suggested.
+	if len(args) == 0 && dashArgs == nil {
+		if len(prefix) == 0 && len(candidates) == 0 {
+			candidates = flagNameCandidates(&cmd.flags)
+		}
+		candidates = append(commandCandidates(&cmd.commands, prefix), candidates...)
+	}
+
+	return c.suggest(prefix, quote, start, candidates)
+}
+
+// commandCandidates returns the names of the given commands.
+// Aliases are only included, if the prefix is not empty.
+func commandCandidates(cmds *Commands, prefix string) (candidates []Candidate) {
+	for _, cmd := range cmds.list {
+		if cmd.hidden() {
+			continue
+		}
+
+		group := cmd.HelpGroup
+		if len(group) == 0 {
+			group = "Commands:"
+		}
+
+		candidates = append(candidates, Candidate{Value: cmd.Name, Description: cmd.Help, Group: group})
+		if len(prefix) > 0 {
 			for _, a := range cmd.Aliases {
-				if strings.HasPrefix(a, prefix) {
-					suggestions = append(suggestions, []rune(strings.TrimPrefix(a, prefix)))
-				}
+				candidates = append(candidates, Candidate{Value: a, Description: cmd.Help, Group: group})
 			}
 		}
+	}
+	return
+}
+
+// flagNameCandidates returns the long and short identifiers of the given flags.
+func flagNameCandidates(flags *Flags) (candidates []Candidate) {
+	for _, f := range flags.list {
+		if f.hidden() {
+			continue
+		}
+
+		candidates = append(candidates, Candidate{Value: "--" + f.Long, Description: f.Help, Group: "Flags:"})
+		if len(f.Short) > 0 {
+			candidates = append(candidates, Candidate{Value: "-" + f.Short, Description: f.Help, Group: "Flags:"})
+		}
+	}
+	return
+}
+
+// suggest filters the candidates by the prefix and returns the remaining,
+// escaped suffixes for readline. Open quotes are closed and a space is
+// appended, unless the candidate ends with a slash, like a directory path.
+// If readline can not list the candidates, because they do not start with the
+// prefix or descriptions are enabled, they are printed to the output and the
+// typed word starting at start is replaced by the unique candidate or the
+// common prefix of all candidates.
+func (c *completer) suggest(prefix string, quote rune, start int, candidates []Candidate) (suggestions [][]rune, length int) {
+	matches := c.match.filter(prefix, candidates)
+	if len(matches) == 0 {
+		return nil, len(prefix)
+	}
+
+	isPrefix := true
+	for _, m := range matches {
+		if !strings.HasPrefix(m.Value, prefix) {
+			isPrefix = false
+			break
+		}
+	}
+
+	// Let readline list the candidates.
+	if isPrefix && (!c.descriptions || c.out == nil || len(matches) == 1) {
+		for _, m := range matches {
+			suggestions = append(suggestions, []rune(completion(strings.TrimPrefix(m.Value, prefix), m.Value, quote)))
+		}
+		return suggestions, len(prefix)
+	}
+
+	// Replace the typed word with the unique candidate.
+	if len(matches) == 1 {
+		c.replace(start, quote, completion(matches[0].Value, matches[0].Value, quote))
+		return [][]rune{{}}, len(prefix)
+	} else if c.out == nil {
+		return nil, len(prefix)
+	}
+
+	printCandidates(c.out, matches, c.descriptions, c.colorDesc)
+
+	// Complete the common prefix of all candidates, if it still matches the typed word.
+	common := matches[0].Value
+	for _, m := range matches[1:] {
+		common = commonPrefix(common, m.Value)
+	}
+	if _, ok := c.match.match(common, prefix); !ok || len(common) < len(prefix) {
+		return [][]rune{{}}, len(prefix)
+	} else if isPrefix {
+		return [][]rune{[]rune(escape(strings.TrimPrefix(common, prefix), quote))}, len(prefix)
+	}
+
+	c.replace(start, quote, escape(common, quote))
+	return [][]rune{{}}, len(prefix)
+}
+
+// replace requests the listener to replace the typed word starting at start.
+// An open quote is part of the typed word and must be restored.
+func (c *completer) replace(start int, quote rune, value string) {
+	if quote != 0 {
+		value = string(quote) + value
+	}
+	c.replacement = &replacement{start: start, value: []rune(value)}
+}
+
+// completion returns the escaped completion for the suffix of the value.
+// Open quotes are closed and a space is appended, unless the value ends
+// with a slash, like a directory path.
+func completion(suffix, value string, quote rune) string {
+	s := escape(suffix, quote)
+	if !strings.HasSuffix(value, "/") {
+		if quote != 0 {
+			s += string(quote)
+		}
+		s += " "
+	}
+	return s
+}
+
+// printCandidates prints the candidates, optionally with their descriptions,
+// in columns. The candidates are grouped in order of the first appearance of
+// each group. The output is written at once, because readline redraws the
+// prompt on each write.
+func printCandidates(w io.Writer, candidates []Candidate, descriptions bool, colorDesc func(string) string) {
+	config := columnize.DefaultConfig()
+	config.Delim = "|"
+	config.Glue = "  "
+	config.Prefix = "  "
 
-		if flags != nil {
-			for _, f := range flags.list {
-				if len(f.Short) > 0 {
-					short := "-" + f.Short
-					if len(prefix) < len(short) && strings.HasPrefix(short, prefix) {
-						suggestions = append(suggestions, []rune(strings.TrimPrefix(short, prefix)))
-					}
-				}
-				long := "--" + f.Long
-				if len(prefix) < len(long) && strings.HasPrefix(long, prefix) {
-					suggestions = append(suggestions, []rune(strings.TrimPrefix(long, prefix)))
-				}
+	var groups []string
+	grouped := make(map[string][]string)
+	for _, cand := range candidates {
+		if _, ok := grouped[cand.Group]; !ok {
+			groups = append(groups, cand.Group)
+		}
+		if descriptions {
+			desc := cand.Description
+			if colorDesc != nil {
+				desc = colorDesc(desc)
 			}
+			grouped[cand.Group] = append(grouped[cand.Group], fmt.Sprintf("%s | %s", cand.Value, desc))
+		} else {
+			grouped[cand.Group] = append(grouped[cand.Group], cand.Value)
 		}
-	} else {
-		for _, cmd := range cmds.list {
-			suggestions = append(suggestions, []rune(cmd.Name))
+	}
+
+	var b strings.Builder
+	for _, g := range groups {
+		if len(g) > 0 {
+			b.WriteString(g + "\n")
 		}
-		if flags != nil {
-			for _, f := range flags.list {
-				suggestions = append(suggestions, []rune("--"+f.Long))
-				if len(f.Short) > 0 {
-					suggestions = append(suggestions, []rune("-"+f.Short))
-				}
+		b.WriteString(columnize.Format(grouped[g], config) + "\n")
+	}
+	_, _ = io.WriteString(w, b.String())
+}
+
+// commonPrefix returns the longest common prefix of a and b.
+func commonPrefix(a, b string) string {
+	ar, br := []rune(a), []rune(b)
+	i := 0
+	for i < len(ar) && i < len(br) && ar[i] == br[i] {
+		i++
+	}
+	return string(ar[:i])
+}
+
+// escape escapes all characters in s, which would be split or
+// removed by the shell, either within the quote or unquoted.
+func escape(s string, quote rune) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch quote {
+		case 0:
+			if unicode.IsSpace(r) || r == '"' || r == '\'' || r == '\\' {
+				b.WriteRune('\\')
+			}
+		case '"':
+			if r == '"' || r == '\\' {
+				b.WriteRune('\\')
 			}
 		}
+		b.WriteRune(r)
 	}
+	return b.String()
+}
 
-	// Append an empty space to each suggestions.
-	for i, s := range suggestions {
-		suggestions[i] = append(s, ' ')
+// lastWordStart returns the index of the first rune of the last word in the line.
+func lastWordStart(line []rune) int {
+	var (
+		start   int
+		quote   rune
+		escaped bool
+	)
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case unicode.IsSpace(r):
+			start = i + 1
+		}
 	}
+	return start
+}
 
-	return suggestions, len(prefix)
+// splitOpenQuote splits the line, which ends within an unclosed quote.
+// Returns nil words, if the line can not be split.
+func splitOpenQuote(line string) ([]string, rune) {
+	for _, q := range []rune{'"', '\''} {
+		if w, err := shlex.Split(line+string(q), true); err == nil {
+			return w, q
+		}
+	}
+	return nil, 0
 }
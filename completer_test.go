@@ -0,0 +1,331 @@
+package grumble
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/desertbit/readline"
+)
+
+// newTestCompleter creates a completer for a small command tree:
+//
+//	get [--namespace ns] pod
+//	admin
+//	  users
+func newTestCompleter() *completer {
+	var c Commands
+
+	get := &Command{
+		Name: "get",
+		Help: "get a pod",
+		Flags: func(f *Flags) {
+			f.String("n", "namespace", "default", "the namespace",
+				FlagCompleter(func(prefix string, flags FlagMap, args ArgMap) []string {
+					return []string{"default", "prod", "staging"}
+				}),
+			)
+		},
+		Args: func(a *Args) {
+			a.String("pod", "the pod name",
+				ArgCompleter(func(prefix string, flags FlagMap, args ArgMap) []string {
+					if flags.String("namespace") == "prod" {
+						return []string{"api", "web"}
+					}
+					return []string{"test"}
+				}),
+			)
+			a.StringList("containers", "the container names",
+				ArgCompleter(func(prefix string, flags FlagMap, args ArgMap) []string {
+					return []string{args.String("pod") + "-main", args.String("pod") + "-sidecar"}
+				}),
+			)
+		},
+	}
+	get.registerFlagsAndArgs(true)
+	c.Add(get)
+
+	admin := &Command{Name: "admin", Help: "admin tools"}
+	admin.registerFlagsAndArgs(true)
+	admin.AddCommand(&Command{Name: "users", Help: "list users"})
+	c.Add(admin)
+
+	return newCompleter(&c)
+}
+
+// complete runs the completer on the line and returns the sorted suggestions.
+func complete(c *completer, line string) []string {
+	r := []rune(line)
+	newLine, _ := c.Do(r, len(r))
+
+	s := make([]string, len(newLine))
+	for i, l := range newLine {
+		s[i] = string(l)
+	}
+	sort.Strings(s)
+	return s
+}
+
+// ---------------------------------------------------------------------------
+// TestCompleterDo
+// ---------------------------------------------------------------------------
+
+func TestCompleterDo(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{name: "top-level commands", line: "ad", want: []string{"min "}},
+		{name: "sub commands", line: "admin u", want: []string{"sers "}},
+		{name: "flag names", line: "get --n", want: []string{"amespace "}},
+		{name: "flag value", line: "get --namespace ", want: []string{"default ", "prod ", "staging "}},
+		{name: "flag value with prefix", line: "get -n st", want: []string{"aging "}},
+		{name: "joined flag value", line: "get --namespace=p", want: []string{"rod "}},
+		{name: "first arg", line: "get t", want: []string{"est "}},
+		{name: "arg narrowed by flag", line: "get --namespace prod ", want: []string{"api ", "web "}},
+		{name: "arg narrowed by trailing flag", line: "get -n prod w", want: []string{"eb "}},
+		{name: "list arg with parsed args", line: "get -n prod api ", want: []string{"api-main ", "api-sidecar "}},
+		{name: "flags after positional", line: "get api --n", want: []string{"amespace "}},
+		{name: "no flags after double dash", line: "get api -- --n", want: nil},
+		{name: "help skips args", line: "help get ", want: []string{"--help ", "--namespace ", "-h ", "-n "}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := complete(newTestCompleter(), tt.line)
+			if len(got) != 0 || len(tt.want) != 0 {
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("expected %q, got %q", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCompleterLegacyCompleter
+// ---------------------------------------------------------------------------
+
+func TestCompleterLegacyCompleter(t *testing.T) {
+	var c Commands
+	cmd := &Command{
+		Name: "legacy",
+		Help: "legacy completer",
+		Completer: func(prefix string, args []string) []string {
+			return []string{prefix + "x"}
+		},
+	}
+	cmd.registerFlagsAndArgs(true)
+	c.Add(cmd)
+
+	got := complete(newCompleter(&c), "legacy a")
+	if !reflect.DeepEqual(got, []string{"x "}) {
+		t.Fatalf("expected [x ], got %q", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCompleterCandidates
+// ---------------------------------------------------------------------------
+
+func TestCompleterCandidates(t *testing.T) {
+	var c Commands
+	cmd := &Command{
+		Name: "deploy",
+		Help: "deploy an app",
+		Args: func(a *Args) {
+			a.String("app", "the app",
+				ArgCandidates(func(prefix string, flags FlagMap, args ArgMap) []Candidate {
+					return []Candidate{
+						{Value: "api-gateway", Description: "the gateway", Group: "Services:"},
+						{Value: "api-server", Description: "the server", Group: "Services:"},
+						{Value: "web", Description: "the frontend", Group: "Frontends:"},
+					}
+				}),
+			)
+		},
+	}
+	cmd.registerFlagsAndArgs(true)
+	c.Add(cmd)
+	c.Add(&Command{Name: "delete", Help: "delete an app", HelpGroup: "Danger:"})
+
+	t.Run("plain", func(t *testing.T) {
+		got := complete(newCompleter(&c), "deploy api")
+		if !reflect.DeepEqual(got, []string{"-gateway ", "-server "}) {
+			t.Fatalf("expected [-gateway -server], got %q", got)
+		}
+	})
+
+	t.Run("descriptions", func(t *testing.T) {
+		var b strings.Builder
+		cp := newCompleter(&c)
+		cp.out = &b
+		cp.descriptions = true
+
+		got := complete(cp, "deploy ")
+		if !reflect.DeepEqual(got, []string{""}) {
+			t.Fatalf("expected only the empty common prefix, got %q", got)
+		}
+		want := "Services:\n  api-gateway  the gateway\n  api-server   the server\nFrontends:\n  web  the frontend\n"
+		if b.String() != want {
+			t.Fatalf("expected output:\n%s\ngot:\n%s", want, b.String())
+		}
+	})
+
+	t.Run("descriptions common prefix", func(t *testing.T) {
+		var b strings.Builder
+		cp := newCompleter(&c)
+		cp.out = &b
+		cp.descriptions = true
+
+		got := complete(cp, "deploy a")
+		if !reflect.DeepEqual(got, []string{"pi-"}) {
+			t.Fatalf("expected common prefix [pi-], got %q", got)
+		}
+
+		b.Reset()
+		complete(cp, "de")
+		for _, s := range []string{"Commands:", "deploy an app", "Danger:", "delete an app"} {
+			if !strings.Contains(b.String(), s) {
+				t.Fatalf("expected output to contain %q, got:\n%s", s, b.String())
+			}
+		}
+	})
+
+	t.Run("descriptions single match", func(t *testing.T) {
+		var b strings.Builder
+		cp := newCompleter(&c)
+		cp.out = &b
+		cp.descriptions = true
+
+		got := complete(cp, "deploy w")
+		if !reflect.DeepEqual(got, []string{"eb "}) || b.Len() != 0 {
+			t.Fatalf("expected [eb ] without output, got %q and %q", got, b.String())
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// TestCompleterMatchModes
+// ---------------------------------------------------------------------------
+
+func TestCompleterMatchModes(t *testing.T) {
+	var c Commands
+	for _, name := range []string{"deploy", "delete", "list"} {
+		cmd := &Command{Name: name, Help: name + " help"}
+		cmd.registerFlagsAndArgs(true)
+		c.Add(cmd)
+	}
+	use := &Command{
+		Name: "use",
+		Help: "use an environment",
+		Completer: func(prefix string, args []string) []string {
+			return []string{"Production", "staging"}
+		},
+	}
+	use.registerFlagsAndArgs(true)
+	c.Add(use)
+
+	// tab completes the line and applies the replacement of the listener.
+	tab := func(cp *completer, line string) string {
+		r := []rune(line)
+		newLine, _ := cp.Do(r, len(r))
+		if len(newLine) == 1 {
+			r = append(r, newLine[0]...)
+		}
+		if l, pos, ok := cp.OnChange(r, len(r), readline.CharTab); ok {
+			r = l[:pos]
+		}
+		return string(r)
+	}
+
+	tests := []struct {
+		name    string
+		mode    MatchMode
+		line    string
+		want    string
+		wantOut []string
+	}{
+		{name: "prefix", mode: MatchPrefix, line: "DEP", want: "DEP"},
+		{name: "prefix fold unique", mode: MatchPrefixFold, line: "DEP", want: "deploy "},
+		{name: "prefix fold common", mode: MatchPrefixFold, line: "DE", want: "de", wantOut: []string{"deploy", "delete"}},
+		{name: "substring unique", mode: MatchSubstring, line: "is", want: "list "},
+		{name: "fuzzy unique", mode: MatchFuzzy, line: "dpl", want: "deploy "},
+		{name: "fuzzy prefix matches", mode: MatchFuzzy, line: "de", want: "de"},
+		{name: "fuzzy listed", mode: MatchFuzzy, line: "dl", want: "dl", wantOut: []string{"deploy", "delete"}},
+		{name: "fuzzy flags", mode: MatchFuzzy, line: "list --hp", want: "list --help "},
+		{name: "prefix custom completer", mode: MatchPrefix, line: "use PROD", want: "use PROD"},
+		{name: "prefix fold custom completer", mode: MatchPrefixFold, line: "use PROD", want: "use Production "},
+		{name: "fuzzy custom completer", mode: MatchFuzzy, line: "use stg", want: "use staging "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			cp := newCompleter(&c)
+			cp.match = tt.mode
+			cp.out = &b
+
+			if got := tab(cp, tt.line); got != tt.want {
+				t.Fatalf("expected line %q, got %q", tt.want, got)
+			}
+			for _, s := range tt.wantOut {
+				if !strings.Contains(b.String(), s) {
+					t.Fatalf("expected output to contain %q, got:\n%s", s, b.String())
+				}
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCompleterAbbreviations
+// ---------------------------------------------------------------------------
+
+func TestCompleterAbbreviations(t *testing.T) {
+	c := newTestCompleter()
+	c.commands.SetAbbreviations(true)
+
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{line: "adm u", want: []string{"sers "}},
+		{line: "g -n p", want: []string{"rod "}},
+		{line: "g --namespace prod w", want: []string{"eb "}},
+	}
+
+	for _, tt := range tests {
+		got := complete(c, tt.line)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: expected %q, got %q", tt.line, tt.want, got)
+		}
+	}
+}
+
+func TestCompleterCallerListener(t *testing.T) {
+	a := New(&Config{Name: "app", Autosuggestions: true})
+
+	var calls int
+	config := &readline.Config{
+		Listener: readline.FuncListener(func(line []rune, pos int, key rune) ([]rune, int, bool) {
+			calls++
+			return nil, 0, false
+		}),
+	}
+
+	// The defaults are set by Run and again by RunWithReadline.
+	a.setReadlineDefaults(config)
+	a.setReadlineDefaults(config)
+
+	ls, ok := config.Listener.(listeners)
+	if !ok || len(ls) != 3 {
+		t.Fatalf("unexpected listeners %#v", config.Listener)
+	}
+	ls.OnChange([]rune("x"), 1, 'x')
+	if calls != 1 {
+		t.Errorf("the caller listener was called %d times", calls)
+	}
+}
@@ -52,11 +52,51 @@ type Config struct {
 	HistoryLimit int
 
 	// NoColor defines if color output should be disabled.
+	// Colors are disabled automatically, if the NO_COLOR environment
+	// variable is set or the output is not a terminal.
 	NoColor bool
 
+	// Theme sets all colors, which are not set explicitly.
+	// Defaults to DefaultTheme.
+	Theme *Theme
+
 	// VimMode defines if Readline is to use VimMode for line navigation.
 	VimMode bool
 
+	// CompletionDescriptions prints the completion candidates with their
+	// descriptions and groups instead of the plain readline candidate list.
+	CompletionDescriptions bool
+
+	// CompletionMatch defines how completion candidates are matched.
+	// Defaults to MatchPrefix.
+	CompletionMatch MatchMode
+
+	// SuggestionDistance is the max edit distance of suggested names for unknown
+	// commands and flags, it's 2 by default, set it to -1 to disable suggestions.
+	SuggestionDistance int
+
+	// PromptMissing asks for missing required args and flags
+	// interactively within the shell.
+	PromptMissing bool
+
+	// AssumeYes skips the confirmation of all commands, like passing --yes.
+	// Use it to run commands from scripts.
+	AssumeYes bool
+
+	// CommandAbbreviations allows to abbreviate command and sub command names
+	// by any unique prefix, for example 'sh int' for 'show interfaces'.
+	CommandAbbreviations bool
+
+	// RCFile is executed at the shell start after the shell hook. It can set
+	// variables, aliases, the prompt and colors and run commands.
+	// Defaults to ~/.<name>rc. NoRCFile or the --norc flag disable it.
+	RCFile   string
+	NoRCFile bool
+
+	// ExpandEnv expands environment variables in the shell input like the
+	// variables set by the rc file. Unknown variables are kept as they are.
+	ExpandEnv bool
+
 	// Prompt defines the shell prompt.
 	Prompt      string
 	PromptColor *color.Color
@@ -68,12 +108,59 @@ type Config struct {
 	// Some more optional color settings.
 	ASCIILogoColor *color.Color
 	ErrorColor     *color.Color
+	WarningColor   *color.Color
+
+	// SyntaxHighlighting highlights the input line as it is typed.
+	// Unknown commands and invalid flags are highlighted with the ErrorColor.
+	SyntaxHighlighting bool
+	CommandColor       *color.Color
+	FlagColor          *color.Color
+	StringColor        *color.Color
+	VariableColor      *color.Color
+
+	// Autosuggestions shows the latest history entry starting with the typed
+	// line as grey text after the cursor. Entries valid for the commands are
+	// preferred. The suggestion is accepted with the right arrow or end key.
+	Autosuggestions     bool
+	AutosuggestionColor *color.Color
+
+	// CompletionColor colors the descriptions of the completion candidates.
+	CompletionColor *color.Color
+
+	// TableHeaderColor colors the header row of tables.
+	// Defaults to the HelpHeadlineColor.
+	TableHeaderColor *color.Color
 
 	// Help styling.
 	HelpHeadlineUnderline bool
 	HelpSubCommands       bool
 	HelpHeadlineColor     *color.Color
 
+	// HelpTemplate and CommandHelpTemplate are the text/template sources of
+	// the app and command help. They are executed with a *HelpData.
+	// Defaults to DefaultHelpTemplate and DefaultCommandHelpTemplate.
+	HelpTemplate        string
+	CommandHelpTemplate string
+
+	// HelpWidth is the width the help is wrapped to. The terminal width
+	// is used by default, set it to -1 to disable wrapping.
+	HelpWidth int
+
+	// Pager is the command used to page output exceeding the terminal height,
+	// like the help. Defaults to the PAGER environment variable. The builtin
+	// pager is used, if both are empty.
+	Pager string
+
+	// NoPager disables paging.
+	NoPager bool
+
+	// Transcript records the shell session to the file, which can also be
+	// started and stopped with the record command. TranscriptFormat is either
+	// TranscriptText or TranscriptAsciinema. If empty, files with the .cast
+	// extension are written as asciinema recording.
+	Transcript       string
+	TranscriptFormat string
+
 	// Override default iterrupt handler
 	InterruptHandler func(a *App, count int)
 }
@@ -83,9 +170,20 @@ func (c *Config) SetDefaults() {
 	if c.HistoryLimit == 0 {
 		c.HistoryLimit = 500
 	}
-	if c.PromptColor == nil {
-		c.PromptColor = color.New(color.FgYellow, color.Bold)
+	if len(c.HelpTemplate) == 0 {
+		c.HelpTemplate = DefaultHelpTemplate
+	}
+	if len(c.CommandHelpTemplate) == 0 {
+		c.CommandHelpTemplate = DefaultCommandHelpTemplate
+	}
+	if c.SuggestionDistance == 0 {
+		c.SuggestionDistance = 2
+	}
+	// The colors, which are not set explicitly, are set by the theme.
+	if c.Theme != nil {
+		c.Theme.apply(c, false)
 	}
+	DefaultTheme().apply(c, false)
 	if len(c.Prompt) == 0 {
 		c.Prompt = c.Name + " » "
 	}
@@ -98,8 +196,8 @@ func (c *Config) SetDefaults() {
 	if c.ASCIILogoColor == nil {
 		c.ASCIILogoColor = c.PromptColor
 	}
-	if c.ErrorColor == nil {
-		c.ErrorColor = color.New(color.FgRed, color.Bold)
+	if c.TableHeaderColor == nil {
+		c.TableHeaderColor = c.HelpHeadlineColor
 	}
 }
 
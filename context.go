@@ -24,6 +24,12 @@
 
 package grumble
 
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
 // Context defines a command context.
 type Context struct {
 	// Reference to the app.
@@ -35,8 +41,23 @@ type Context struct {
 	// Args contains all command line arguments.
 	Args ArgMap
 
+	// RestArgs contains the command line arguments following a
+	// double dash (--), which have not been consumed by Args.
+	RestArgs []string
+
 	// Cmd is the currently executing command.
 	Command *Command
+
+	result interface{}
+
+	progressMutex   sync.Mutex
+	progress        []*Progress
+	progressStopped bool // Set when the command returned.
+
+	// Override the app output, e.g. to capture it.
+	// The context can't prompt for input then.
+	stdout io.Writer
+	stderr io.Writer
 }
 
 func newContext(a *App, cmd *Command, flags FlagMap, args ArgMap) *Context {
@@ -48,7 +69,117 @@ func newContext(a *App, cmd *Command, flags FlagMap, args ArgMap) *Context {
 	}
 }
 
+// SetResult sets the structured result of the command.
+// It is returned by the HTTP handler in addition to the output.
+func (c *Context) SetResult(v interface{}) {
+	c.result = v
+}
+
+// Result returns the structured result set by the command.
+func (c *Context) Result() interface{} {
+	return c.result
+}
+
+// Stdout returns a writer to the command output.
+// It is the app output, unless the output is captured, e.g. by the HTTP handler.
+func (c *Context) Stdout() io.Writer {
+	if c.stdout != nil {
+		return c.stdout
+	}
+	return c.App.Stdout()
+}
+
+// Stderr returns a writer to the command error output.
+// It is the app error output, unless the output is captured.
+func (c *Context) Stderr() io.Writer {
+	if c.stderr != nil {
+		return c.stderr
+	}
+	return c.App.Stderr()
+}
+
+// Write to the command output.
+func (c *Context) Write(p []byte) (int, error) {
+	return c.Stdout().Write(p)
+}
+
+// Print writes to the command output.
+func (c *Context) Print(args ...interface{}) (int, error) {
+	return fmt.Fprint(c, args...)
+}
+
+// Printf formats according to a format specifier and writes to the command output.
+func (c *Context) Printf(format string, args ...interface{}) (int, error) {
+	return fmt.Fprintf(c, format, args...)
+}
+
+// Println writes to the command output followed by a newline.
+func (c *Context) Println(args ...interface{}) (int, error) {
+	return fmt.Fprintln(c, args...)
+}
+
+// interactive returns true, if the user can be asked for input.
+func (c *Context) interactive() bool {
+	return c.stdout == nil && c.App.interactive()
+}
+
+// checkInput returns ErrNotInteractive, if the output is captured.
+// The app checks, if it has any input.
+func (c *Context) checkInput() error {
+	if c.stdout != nil {
+		return ErrNotInteractive
+	}
+	return nil
+}
+
 // Stop signalizes the app to exit.
 func (c *Context) Stop() {
 	_ = c.App.Close()
 }
+
+// Confirm asks a yes or no question. See App.Confirm.
+func (c *Context) Confirm(message string, def bool) (bool, error) {
+	if err := c.checkInput(); err != nil {
+		return false, err
+	}
+	return c.App.Confirm(message, def)
+}
+
+// Input asks for a line of text. See App.Input.
+func (c *Context) Input(po PromptOptions) (string, error) {
+	if err := c.checkInput(); err != nil {
+		return "", err
+	}
+	return c.App.Input(po)
+}
+
+// Password asks for a secret line of text. See App.Password.
+func (c *Context) Password(message string) (string, error) {
+	if err := c.checkInput(); err != nil {
+		return "", err
+	}
+	return c.App.Password(message)
+}
+
+// Select asks to choose one of the choices. See App.Select.
+func (c *Context) Select(message string, choices []string, def string) (string, error) {
+	if err := c.checkInput(); err != nil {
+		return "", err
+	}
+	return c.App.Select(message, choices, def)
+}
+
+// MultiSelect asks to choose any number of the choices. See App.MultiSelect.
+func (c *Context) MultiSelect(message string, choices []string, defaults []string) ([]string, error) {
+	if err := c.checkInput(); err != nil {
+		return nil, err
+	}
+	return c.App.MultiSelect(message, choices, defaults)
+}
+
+// Pager returns a new pager, which pages the output on Close,
+// if it exceeds the terminal height. See App.Pager.
+// Captured output is not paged.
+func (c *Context) Pager() *Pager {
+	return &Pager{a: c.App, out: c.stdout}
+}
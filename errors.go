@@ -0,0 +1,199 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Exit codes used by Main.
+const (
+	// ExitCodeError is returned for any failure without a specific exit code.
+	ExitCodeError = 1
+
+	// ExitCodeUsage is returned for an invalid usage, like an unknown command,
+	// an invalid flag or a missing argument.
+	ExitCodeUsage = 2
+)
+
+var (
+	// ErrUnknownCommand matches an *UnknownCommandError with errors.Is.
+	ErrUnknownCommand = errors.New("unknown command")
+
+	// ErrMissingArgument is wrapped by the *ArgError of a missing argument.
+	ErrMissingArgument = errors.New("missing argument")
+
+	// ErrMissingFlag is wrapped by the *FlagError of a required flag, which is not set.
+	ErrMissingFlag = errors.New("missing required flag")
+
+	// ErrNotInteractive is returned, if input is requested, but the app
+	// does not run an interactive shell.
+	ErrNotInteractive = errors.New("input requires an interactive shell")
+
+	// ErrAborted is returned, if the confirmation of a command is declined.
+	ErrAborted = errors.New("aborted")
+)
+
+// UnknownCommandError is returned, if a command or sub command is not registered.
+type UnknownCommandError struct {
+	// Name of the unknown command.
+	Name string
+
+	// Parent is the name of the parent command, if a sub command is unknown.
+	Parent string
+
+	// Suggestions contains similar command names.
+	Suggestions []string
+}
+
+func (e *UnknownCommandError) Error() string {
+	msg := fmt.Sprintf("unknown command '%s'", e.Name)
+	if len(e.Parent) > 0 {
+		msg = fmt.Sprintf("unknown sub command '%s' of '%s'", e.Name, e.Parent)
+	}
+
+	if s := didYouMean(e.Suggestions); len(s) > 0 {
+		return msg + ", " + s
+	}
+	return msg + ", try 'help'"
+}
+
+// Is reports whether the target is ErrUnknownCommand.
+func (e *UnknownCommandError) Is(target error) bool {
+	return target == ErrUnknownCommand
+}
+
+// AmbiguousCommandError is returned, if an abbreviated command name
+// matches multiple commands.
+type AmbiguousCommandError struct {
+	Name       string
+	Candidates []string
+}
+
+func (e *AmbiguousCommandError) Error() string {
+	quoted := make([]string, len(e.Candidates))
+	for i, c := range e.Candidates {
+		quoted[i] = fmt.Sprintf("'%s'", c)
+	}
+	return fmt.Sprintf("ambiguous command '%s', could be %s", e.Name, strings.Join(quoted, ", "))
+}
+
+// FlagError is returned, if a flag is unknown, its value is missing
+// or the value could not be parsed.
+type FlagError struct {
+	// Flag as passed on the command line for unknown flags,
+	// otherwise the long flag name.
+	Flag string
+	Err  error
+
+	flags *Flags // Set for unknown flags to look up suggestions.
+}
+
+func (e *FlagError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FlagError) Unwrap() error {
+	return e.Err
+}
+
+// ArgError is returned, if an argument is missing or invalid.
+type ArgError struct {
+	// Arg is the argument name.
+	Arg string
+	Err error
+}
+
+func (e *ArgError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ArgError) Unwrap() error {
+	return e.Err
+}
+
+// UsageError is returned, if a command is called with invalid input,
+// which is not related to a single flag or argument.
+type UsageError struct {
+	// Command is the command name.
+	Command string
+	Err     error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// ExitError can be returned by a command to exit Main with a specific code.
+// The error is printed, if Err is not nil.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code for the error. It's 0 for a nil error,
+// the code of an *ExitError, ExitCodeUsage for usage errors and
+// ExitCodeError otherwise.
+func ExitCode(err error) int {
+	var (
+		ee *ExitError
+		fe *FlagError
+		ae *ArgError
+		ue *UsageError
+		ce *AmbiguousCommandError
+	)
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &ee):
+		return ee.Code
+	case errors.Is(err, ErrUnknownCommand),
+		errors.As(err, &fe),
+		errors.As(err, &ae),
+		errors.As(err, &ue),
+		errors.As(err, &ce):
+		return ExitCodeUsage
+	default:
+		return ExitCodeError
+	}
+}
@@ -0,0 +1,128 @@
+package grumble
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestTypedErrors
+// ---------------------------------------------------------------------------
+
+func TestTypedErrors(t *testing.T) {
+	a := newTestApp(t, nil, &Command{
+		Name: "scale",
+		Help: "scale an app",
+		Flags: func(f *Flags) {
+			f.Int("r", "replicas", 1, "number of replicas")
+		},
+		Args: func(a *Args) {
+			a.String("app", "the app name")
+			a.Int("count", "the count", Default(1))
+		},
+		Run: func(c *Context) error {
+			if c.Args.String("app") == "exit" {
+				return &ExitError{Code: 3}
+			}
+			return nil
+		},
+	})
+
+	err := a.RunCommand([]string{"scal"})
+	var uce *UnknownCommandError
+	if !errors.Is(err, ErrUnknownCommand) || !errors.As(err, &uce) || uce.Name != "scal" {
+		t.Errorf("expected unknown command error, got %#v", err)
+	}
+
+	err = a.RunCommand([]string{"scale", "--replica", "2", "app"})
+	var fe *FlagError
+	if !errors.As(err, &fe) || fe.Flag != "--replica" {
+		t.Errorf("expected unknown flag error, got %#v", err)
+	}
+
+	err = a.RunCommand([]string{"scale", "--replicas", "x", "app"})
+	var ne *strconv.NumError
+	if !errors.As(err, &fe) || fe.Flag != "replicas" || !errors.As(err, &ne) {
+		t.Errorf("expected invalid flag value error, got %#v", err)
+	}
+
+	err = a.RunCommand([]string{"scale", "--replicas"})
+	if !errors.As(err, &fe) || fe.Flag != "replicas" {
+		t.Errorf("expected missing flag value error, got %#v", err)
+	}
+
+	err = a.RunCommand([]string{"scale"})
+	var ae *ArgError
+	if !errors.As(err, &ae) || ae.Arg != "app" || err.Error() != "missing argument 'app'" {
+		t.Errorf("expected missing argument error, got %#v", err)
+	}
+
+	err = a.RunCommand([]string{"scale", "app", "x"})
+	if !errors.As(err, &ae) || ae.Arg != "count" {
+		t.Errorf("expected invalid argument error, got %#v", err)
+	}
+
+	err = a.RunCommand([]string{"scale", "app", "1", "2"})
+	var ue *UsageError
+	if !errors.As(err, &ue) || ue.Command != "scale" {
+		t.Errorf("expected usage error, got %#v", err)
+	}
+
+	err = a.RunCommand([]string{"scale", "exit"})
+	var ee *ExitError
+	if !errors.As(err, &ee) || ee.Code != 3 {
+		t.Errorf("expected exit error, got %#v", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestExitCode
+// ---------------------------------------------------------------------------
+
+func TestExitCode(t *testing.T) {
+	a := newTestApp(t, nil, &Command{
+		Name: "scale",
+		Help: "scale an app",
+		Args: func(a *Args) {
+			a.String("app", "the app name")
+			a.Int("count", "the count", Default(1))
+		},
+		Run: func(c *Context) error {
+			if c.Args.String("app") == "fail" {
+				return fmt.Errorf("failed")
+			} else if c.Args.String("app") == "exit" {
+				return &ExitError{Code: 3}
+			}
+			return nil
+		},
+	})
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"scale", "app"}, 0},
+		{[]string{"scale", "fail"}, ExitCodeError},
+		{[]string{"scale", "exit"}, 3},
+		{[]string{"scal"}, ExitCodeUsage},
+		{[]string{"scale", "--replica", "2", "app"}, ExitCodeUsage},
+		{[]string{"scale"}, ExitCodeUsage},
+		{[]string{"scale", "app", "1", "2"}, ExitCodeUsage},
+	}
+
+	for _, tt := range tests {
+		if got := ExitCode(a.RunCommand(tt.args)); got != tt.want {
+			t.Errorf("%v: expected exit code %d, got %d", tt.args, tt.want, got)
+		}
+	}
+
+	wrapped := fmt.Errorf("wrapped: %w", &ExitError{Code: 4, Err: fmt.Errorf("failed")})
+	if got := ExitCode(wrapped); got != 4 {
+		t.Errorf("expected exit code 4 for a wrapped exit error, got %d", got)
+	}
+	if got := wrapped.Error(); got != "wrapped: failed" {
+		t.Errorf("unexpected error message %q", got)
+	}
+}
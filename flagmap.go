@@ -26,6 +26,7 @@ package grumble
 
 import (
 	"fmt"
+	"sort"
 	"time"
 )
 
@@ -50,6 +51,29 @@ func (f FlagMap) copyMissingValues(m FlagMap, copyDefault bool) {
 	}
 }
 
+// Has returns true, if the flag is registered.
+func (f FlagMap) Has(long string) bool {
+	_, ok := f[long]
+	return ok
+}
+
+// IsSet returns true, if the flag has been passed explicitly
+// and does not hold its default value.
+func (f FlagMap) IsSet(long string) bool {
+	fi, ok := f[long]
+	return ok && !fi.IsDefault
+}
+
+// Names returns the sorted long names of all flags.
+func (f FlagMap) Names() []string {
+	names := make([]string, 0, len(f))
+	for k := range f {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // String returns the given flag value as string.
 // Panics if not present. Flags must be registered.
 func (f FlagMap) String(long string) string {
@@ -228,3 +252,15 @@ func (f FlagMap) flagValOrPanic(long string) interface{} {
 	}
 	return fi.Value
 }
+
+func (f FlagMap) lookupValue(long string) (interface{}, bool) {
+	fi, ok := f[long]
+	if !ok {
+		return nil, false
+	}
+	return fi.Value, true
+}
+
+func (f FlagMap) kind() string {
+	return "flag"
+}
@@ -0,0 +1,86 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+// FlagOption can be supplied to modify a flag.
+type FlagOption func(*flagItem)
+
+// FlagCompleter sets the completion function for the flag value.
+func FlagCompleter(f CompleteFunc) FlagOption {
+	if f == nil {
+		panic("nil completer not allowed")
+	}
+
+	return func(i *flagItem) {
+		i.completer = f.candidates()
+	}
+}
+
+// FlagRequired requires the flag to be set.
+func FlagRequired() FlagOption {
+	return func(i *flagItem) {
+		i.required = true
+	}
+}
+
+// FlagPrompt sets the options to ask for the flag value, if the flag
+// is required, but not set and Config.PromptMissing is enabled.
+func FlagPrompt(po PromptOptions) FlagOption {
+	return func(i *flagItem) {
+		i.prompt = &po
+	}
+}
+
+// FlagHidden hides the flag from the help and completion.
+func FlagHidden() FlagOption {
+	return func(i *flagItem) {
+		i.isHidden = true
+	}
+}
+
+// FlagDeprecated marks the flag as deprecated. The message is printed
+// with a warning if the flag is used and should name the replacement.
+// Deprecated flags are hidden from the help and completion.
+func FlagDeprecated(message string) FlagOption {
+	if len(message) == 0 {
+		panic("empty deprecation message not allowed")
+	}
+
+	return func(i *flagItem) {
+		i.deprecated = message
+	}
+}
+
+// FlagCandidates sets the completion function for the flag value,
+// which returns candidates with descriptions.
+func FlagCandidates(f CandidatesFunc) FlagOption {
+	if f == nil {
+		panic("nil completer not allowed")
+	}
+
+	return func(i *flagItem) {
+		i.completer = f
+	}
+}
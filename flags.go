@@ -43,6 +43,17 @@ type flagItem struct {
 
 	parser          flagItemParser
 	allowEmptyValue bool
+	completer       CandidatesFunc
+	isHidden        bool
+	deprecated      string
+	path            *PathOptions // Set for path flags.
+	required        bool
+	prompt          *PromptOptions
+}
+
+// hidden returns true, if the flag should not be listed.
+func (fi *flagItem) hidden() bool {
+	return fi.isHidden || len(fi.deprecated) > 0
 }
 
 // showDefault returns true, if the default parameter should be shown in a help message.
@@ -72,6 +83,30 @@ func (f *Flags) match(flag, short, long string) bool {
 	return (len(short) > 0 && flag == "-"+short) || (len(long) > 0 && flag == "--"+long)
 }
 
+// names returns the long and short identifiers of all visible flags.
+func (f *Flags) names() (names []string) {
+	for _, fi := range f.list {
+		if fi.hidden() {
+			continue
+		}
+		names = append(names, "--"+fi.Long)
+		if len(fi.Short) > 0 {
+			names = append(names, "-"+fi.Short)
+		}
+	}
+	return
+}
+
+// find returns the flag item matching the given flag identifier or nil.
+func (f *Flags) find(flag string) *flagItem {
+	for _, fi := range f.list {
+		if f.match(flag, fi.Short, fi.Long) {
+			return fi
+		}
+	}
+	return nil
+}
+
 // register creates a new flag item in f with the given properties.
 // The parser is a func that receives the parsed value from the arguments
 // and must convert it to the correctly typed value of its flag.
@@ -82,6 +117,7 @@ func (f *Flags) register(
 	defaultValue interface{},
 	allowEmptyValue bool,
 	parser flagItemParser,
+	opts ...FlagOption,
 ) {
 	// Validate.
 	if len(short) > 1 {
@@ -107,8 +143,8 @@ func (f *Flags) register(
 		}
 	}
 
-	// Add the new flag item.
-	f.list = append(f.list, &flagItem{
+	// Create the flag item.
+	item := &flagItem{
 		Short:    short,
 		Long:     long,
 		Help:     help,
@@ -117,79 +153,144 @@ func (f *Flags) register(
 
 		parser:          parser,
 		allowEmptyValue: allowEmptyValue,
-	})
+	}
+
+	// Apply options.
+	for _, opt := range opts {
+		opt(item)
+	}
+
+	f.list = append(f.list, item)
 }
 
 // parse iterates the given args and parses all found flags from it.
+// Parsing stops at the first positional argument or after a double dash (--).
 // The leftover, not parsed arguments are returned.
 // The parsed flag results are written to res.
 func (f *Flags) parse(args []string, res FlagMap) ([]string, error) {
-	// There are 3 ways a flag can be given:
-	//   1. `--flag`       : identifier only.
-	//   2. `--flag value` : identifier and value in separate args.
-	//   3. `--flag=value` : identifier and value joined by '=' in same arg.
+	args, err := f.parseLeading(args, res)
+	if err != nil {
+		return nil, err
+	}
 
-ParseLoop:
-	for len(args) > 0 {
-		// Retrieve the next argument.
-		a := args[0]
+	// A double dash (--) is used to signify the end of command options,
+	// after which only positional arguments are accepted.
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
 
+	return args, nil
+}
+
+// parseLeading parses all flags from the beginning of args up to the first
+// positional argument or double dash (--), which are not consumed.
+// The leftover, not parsed arguments are returned.
+// The parsed flag results are written to res.
+func (f *Flags) parseLeading(args []string, res FlagMap) ([]string, error) {
+	var err error
+	for len(args) > 0 {
 		// If the argument does not start with a hyphen, it is not a flag.
 		// We can stop the parsing loop then.
-		if !strings.HasPrefix(a, "-") {
-			break ParseLoop
+		if !strings.HasPrefix(args[0], "-") || args[0] == "--" {
+			break
 		}
-		args = args[1:] // Pop the consumed argument.
 
-		// A double dash (--) is used to signify the end of command options,
-		// after which only positional arguments are accepted.
+		args, err = f.parseFlag(args, res)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	f.setDefaults(res)
+	return args, nil
+}
+
+// parseInterleaved parses all flags from args, which may be mixed with
+// positional arguments. A double dash (--) ends the flag parsing.
+// The positional arguments are returned in their original order, while
+// all arguments following the double dash are returned verbatim as rest.
+// Rest is nil, if args does not contain a double dash.
+// Negative numbers, which are no registered flags, are positional.
+// The parsed flag results are written to res.
+func (f *Flags) parseInterleaved(args []string, res FlagMap) (positional, rest []string, err error) {
+	for len(args) > 0 {
+		a := args[0]
 		if a == "--" {
-			break ParseLoop
+			rest = args[1:]
+			break
+		} else if !strings.HasPrefix(a, "-") || f.isNegativeNumber(a) {
+			positional = append(positional, a)
+			args = args[1:]
+			continue
 		}
 
-		// Check, if we must parse case 3 of the possible flag formats.
-		flagValue := ""
-		if pos := strings.Index(a, "="); pos > 0 {
-			flagValue = a[pos+1:]
-			a = a[:pos]
+		args, err = f.parseFlag(args, res)
+		if err != nil {
+			return nil, nil, err
 		}
+	}
 
-		// Find the registered flag item.
-		var (
-			parsedVal interface{}
-			err       error
-		)
-		for _, fi := range f.list {
-			if f.match(a, fi.Short, fi.Long) {
-				// Check, if the flag requires a value and if yes,
-				// if there is one left in the arguments.
-				// This is case 2 of the possible flag formats.
-				if !fi.allowEmptyValue && flagValue == "" {
-					if len(args) == 0 {
-						return nil, fmt.Errorf("missing value for flag %s", fi.Long)
-					}
-
-					flagValue = args[0]
-					args = args[1:] // Pop the consumed argument.
-				}
-
-				// Run the parser of this flag against the provided value.
-				parsedVal, err = fi.parser(flagValue)
-				if err != nil {
-					return nil, fmt.Errorf("failed to parse flag %s: %v", fi.Long, err)
-				}
-				res[fi.Long] = &FlagMapItem{Value: parsedVal}
-
-				// Continue to next flag.
-				continue ParseLoop
-			}
+	f.setDefaults(res)
+	return positional, rest, nil
+}
+
+// isNegativeNumber returns true, if the arg is a number like -5 or -1.5
+// and not a registered flag.
+func (f *Flags) isNegativeNumber(a string) bool {
+	if _, err := strconv.ParseFloat(a, 64); err != nil {
+		return false
+	}
+	return f.find(a) == nil
+}
+
+// parseFlag parses the flag at the beginning of args and returns the
+// leftover arguments. The parsed flag result is written to res.
+func (f *Flags) parseFlag(args []string, res FlagMap) ([]string, error) {
+	// There are 3 ways a flag can be given:
+	//   1. `--flag`       : identifier only.
+	//   2. `--flag value` : identifier and value in separate args.
+	//   3. `--flag=value` : identifier and value joined by '=' in same arg.
+	a := args[0]
+	args = args[1:] // Pop the consumed argument.
+
+	// Check, if we must parse case 3 of the possible flag formats.
+	flagValue := ""
+	if pos := strings.Index(a, "="); pos > 0 {
+		flagValue = a[pos+1:]
+		a = a[:pos]
+	}
+
+	// Find the registered flag item.
+	fi := f.find(a)
+	if fi == nil {
+		return nil, &FlagError{Flag: a, Err: fmt.Errorf("invalid flag: %s", a), flags: f}
+	}
+
+	// Check, if the flag requires a value and if yes,
+	// if there is one left in the arguments.
+	// This is case 2 of the possible flag formats.
+	if !fi.allowEmptyValue && flagValue == "" {
+		if len(args) == 0 {
+			return nil, &FlagError{Flag: fi.Long, Err: fmt.Errorf("missing value for flag %s", fi.Long)}
 		}
 
-		return nil, fmt.Errorf("invalid flag: %s", a)
+		flagValue = args[0]
+		args = args[1:] // Pop the consumed argument.
 	}
 
-	// Set the default value for every flag that has not been
-	// provided by the arguments.
+	// Run the parser of this flag against the provided value.
+	parsedVal, err := fi.parser(flagValue)
+	if err != nil {
+		return nil, &FlagError{Flag: fi.Long, Err: fmt.Errorf("failed to parse flag %s: %w", fi.Long, err)}
+	}
+	res[fi.Long] = &FlagMapItem{Value: parsedVal}
+
+	return args, nil
+}
+
+// setDefaults sets the default value for every flag that has not been
+// provided by the arguments.
+func (f *Flags) setDefaults(res FlagMap) {
 	for _, fi := range f.list {
 		if _, ok := res[fi.Long]; !ok {
 			res[fi.Long] = &FlagMapItem{
@@ -198,29 +299,27 @@ ParseLoop:
 			}
 		}
 	}
-
-	return args, nil
 }
 
 // StringL same as String, but without a shorthand.
-func (f *Flags) StringL(long, defaultValue, help string) {
-	f.String("", long, defaultValue, help)
+func (f *Flags) StringL(long, defaultValue, help string, opts ...FlagOption) {
+	f.String("", long, defaultValue, help, opts...)
 }
 
 // String registers a string flag.
-func (f *Flags) String(short, long, defaultValue, help string) {
+func (f *Flags) String(short, long, defaultValue, help string, opts ...FlagOption) {
 	f.register(short, long, help, "string", defaultValue, false, func(value string) (interface{}, error) {
 		return trimQuotes(value), nil
-	})
+	}, opts...)
 }
 
 // StringListL same as StringList, but without a shorthand.
-func (f *Flags) StringListL(long string, defaultValue []string, help string) {
-	f.StringList("", long, defaultValue, help)
+func (f *Flags) StringListL(long string, defaultValue []string, help string, opts ...FlagOption) {
+	f.StringList("", long, defaultValue, help, opts...)
 }
 
 // StringList registers a string list flag.
-func (f *Flags) StringList(short, long string, defaultValue []string, help string) {
+func (f *Flags) StringList(short, long string, defaultValue []string, help string, opts ...FlagOption) {
 	// Convert []string default to []interface{} so FlagMap.StringList() can assert it.
 	def := make([]interface{}, len(defaultValue))
 	for i, v := range defaultValue {
@@ -229,180 +328,199 @@ func (f *Flags) StringList(short, long string, defaultValue []string, help strin
 
 	f.register(short, long, help, "stringList", def, false, func(value string) (interface{}, error) {
 		return []interface{}{trimQuotes(value)}, nil
-	})
+	}, opts...)
 }
 
 // BoolL same as Bool, but without a shorthand.
-func (f *Flags) BoolL(long string, defaultValue bool, help string) {
-	f.Bool("", long, defaultValue, help)
+func (f *Flags) BoolL(long string, defaultValue bool, help string, opts ...FlagOption) {
+	f.Bool("", long, defaultValue, help, opts...)
 }
 
 // Bool registers a boolean flag.
-func (f *Flags) Bool(short, long string, defaultValue bool, help string) {
+func (f *Flags) Bool(short, long string, defaultValue bool, help string, opts ...FlagOption) {
 	f.register(short, long, help, "bool", defaultValue, true, func(value string) (interface{}, error) {
 		// For bool flags the value is optional.
 		if value == "" {
 			return true, nil
 		}
 		return strconv.ParseBool(value)
-	})
+	}, opts...)
 }
 
 // IntL same as Int, but without a shorthand.
-func (f *Flags) IntL(long string, defaultValue int, help string) {
-	f.Int("", long, defaultValue, help)
+func (f *Flags) IntL(long string, defaultValue int, help string, opts ...FlagOption) {
+	f.Int("", long, defaultValue, help, opts...)
 }
 
 // Int registers an int flag.
-func (f *Flags) Int(short, long string, defaultValue int, help string) {
+func (f *Flags) Int(short, long string, defaultValue int, help string, opts ...FlagOption) {
 	f.register(short, long, help, "int", defaultValue, false, func(value string) (interface{}, error) {
 		return strToInt(value)
-	})
+	}, opts...)
 }
 
 // Int8L same as Int8, but without a shorthand.
-func (f *Flags) Int8L(long string, defaultValue int8, help string) {
-	f.Int8("", long, defaultValue, help)
+func (f *Flags) Int8L(long string, defaultValue int8, help string, opts ...FlagOption) {
+	f.Int8("", long, defaultValue, help, opts...)
 }
 
 // Int8 registers an int8 flag.
-func (f *Flags) Int8(short, long string, defaultValue int8, help string) {
+func (f *Flags) Int8(short, long string, defaultValue int8, help string, opts ...FlagOption) {
 	f.register(short, long, help, "int8", defaultValue, false, func(value string) (interface{}, error) {
 		return strToInt8(value)
-	})
+	}, opts...)
 }
 
 // Int16L same as Int16, but without a shorthand.
-func (f *Flags) Int16L(long string, defaultValue int16, help string) {
-	f.Int16("", long, defaultValue, help)
+func (f *Flags) Int16L(long string, defaultValue int16, help string, opts ...FlagOption) {
+	f.Int16("", long, defaultValue, help, opts...)
 }
 
 // Int16 registers an int16 flag.
-func (f *Flags) Int16(short, long string, defaultValue int16, help string) {
+func (f *Flags) Int16(short, long string, defaultValue int16, help string, opts ...FlagOption) {
 	f.register(short, long, help, "int16", defaultValue, false, func(value string) (interface{}, error) {
 		return strToInt16(value)
-	})
+	}, opts...)
 }
 
 // Int32L same as Int32, but without a shorthand.
-func (f *Flags) Int32L(long string, defaultValue int32, help string) {
-	f.Int32("", long, defaultValue, help)
+func (f *Flags) Int32L(long string, defaultValue int32, help string, opts ...FlagOption) {
+	f.Int32("", long, defaultValue, help, opts...)
 }
 
 // Int32 registers an int32 flag.
-func (f *Flags) Int32(short, long string, defaultValue int32, help string) {
+func (f *Flags) Int32(short, long string, defaultValue int32, help string, opts ...FlagOption) {
 	f.register(short, long, help, "int32", defaultValue, false, func(value string) (interface{}, error) {
 		return strToInt32(value)
-	})
+	}, opts...)
 }
 
 // Int64L same as Int64, but without a shorthand.
-func (f *Flags) Int64L(long string, defaultValue int64, help string) {
-	f.Int64("", long, defaultValue, help)
+func (f *Flags) Int64L(long string, defaultValue int64, help string, opts ...FlagOption) {
+	f.Int64("", long, defaultValue, help, opts...)
 }
 
 // Int64 registers an int64 flag.
-func (f *Flags) Int64(short, long string, defaultValue int64, help string) {
+func (f *Flags) Int64(short, long string, defaultValue int64, help string, opts ...FlagOption) {
 	f.register(short, long, help, "int64", defaultValue, false, func(value string) (interface{}, error) {
 		return strToInt64(value)
-	})
+	}, opts...)
 }
 
 // UintL same as Uint, but without a shorthand.
-func (f *Flags) UintL(long string, defaultValue uint, help string) {
-	f.Uint("", long, defaultValue, help)
+func (f *Flags) UintL(long string, defaultValue uint, help string, opts ...FlagOption) {
+	f.Uint("", long, defaultValue, help, opts...)
 }
 
 // Uint registers an uint flag.
-func (f *Flags) Uint(short, long string, defaultValue uint, help string) {
+func (f *Flags) Uint(short, long string, defaultValue uint, help string, opts ...FlagOption) {
 	f.register(short, long, help, "uint", defaultValue, false, func(value string) (interface{}, error) {
 		return strToUint(value)
-	})
+	}, opts...)
 }
 
 // Uint8L same as Uint8, but without a shorthand.
-func (f *Flags) Uint8L(long string, defaultValue uint8, help string) {
-	f.Uint8("", long, defaultValue, help)
+func (f *Flags) Uint8L(long string, defaultValue uint8, help string, opts ...FlagOption) {
+	f.Uint8("", long, defaultValue, help, opts...)
 }
 
 // Uint8 registers an uint8 flag.
-func (f *Flags) Uint8(short, long string, defaultValue uint8, help string) {
+func (f *Flags) Uint8(short, long string, defaultValue uint8, help string, opts ...FlagOption) {
 	f.register(short, long, help, "uint8", defaultValue, false, func(value string) (interface{}, error) {
 		return strToUint8(value)
-	})
+	}, opts...)
 }
 
 // Uint16L same as Uint16, but without a shorthand.
-func (f *Flags) Uint16L(long string, defaultValue uint16, help string) {
-	f.Uint16("", long, defaultValue, help)
+func (f *Flags) Uint16L(long string, defaultValue uint16, help string, opts ...FlagOption) {
+	f.Uint16("", long, defaultValue, help, opts...)
 }
 
 // Uint16 registers an uint16 flag.
-func (f *Flags) Uint16(short, long string, defaultValue uint16, help string) {
+func (f *Flags) Uint16(short, long string, defaultValue uint16, help string, opts ...FlagOption) {
 	f.register(short, long, help, "uint16", defaultValue, false, func(value string) (interface{}, error) {
 		return strToUint16(value)
-	})
+	}, opts...)
 }
 
 // Uint32L same as Uint32, but without a shorthand.
-func (f *Flags) Uint32L(long string, defaultValue uint32, help string) {
-	f.Uint32("", long, defaultValue, help)
+func (f *Flags) Uint32L(long string, defaultValue uint32, help string, opts ...FlagOption) {
+	f.Uint32("", long, defaultValue, help, opts...)
 }
 
 // Uint32 registers an uint32 flag.
-func (f *Flags) Uint32(short, long string, defaultValue uint32, help string) {
+func (f *Flags) Uint32(short, long string, defaultValue uint32, help string, opts ...FlagOption) {
 	f.register(short, long, help, "uint32", defaultValue, false, func(value string) (interface{}, error) {
 		return strToUint32(value)
-	})
+	}, opts...)
 }
 
 // Uint64L same as Uint64, but without a shorthand.
-func (f *Flags) Uint64L(long string, defaultValue uint64, help string) {
-	f.Uint64("", long, defaultValue, help)
+func (f *Flags) Uint64L(long string, defaultValue uint64, help string, opts ...FlagOption) {
+	f.Uint64("", long, defaultValue, help, opts...)
 }
 
 // Uint64 registers an uint64 flag.
-func (f *Flags) Uint64(short, long string, defaultValue uint64, help string) {
+func (f *Flags) Uint64(short, long string, defaultValue uint64, help string, opts ...FlagOption) {
 	f.register(short, long, help, "uint64", defaultValue, false, func(value string) (interface{}, error) {
 		return strToUint64(value)
-	})
+	}, opts...)
 }
 
 // Float32L same as Float32, but without a shorthand.
-func (f *Flags) Float32L(long string, defaultValue float32, help string) {
-	f.Float32("", long, defaultValue, help)
+func (f *Flags) Float32L(long string, defaultValue float32, help string, opts ...FlagOption) {
+	f.Float32("", long, defaultValue, help, opts...)
 }
 
 // Float32 registers an float32 flag.
-func (f *Flags) Float32(short, long string, defaultValue float32, help string) {
+func (f *Flags) Float32(short, long string, defaultValue float32, help string, opts ...FlagOption) {
 	f.register(short, long, help, "float32", defaultValue, false, func(value string) (interface{}, error) {
 		v, err := strconv.ParseFloat(value, 32)
 		return float32(v), err
-	})
+	}, opts...)
 }
 
 // Float64L same as Float64, but without a shorthand.
-func (f *Flags) Float64L(long string, defaultValue float64, help string) {
-	f.Float64("", long, defaultValue, help)
+func (f *Flags) Float64L(long string, defaultValue float64, help string, opts ...FlagOption) {
+	f.Float64("", long, defaultValue, help, opts...)
 }
 
 // Float64 registers an float64 flag.
-func (f *Flags) Float64(short, long string, defaultValue float64, help string) {
+func (f *Flags) Float64(short, long string, defaultValue float64, help string, opts ...FlagOption) {
 	f.register(short, long, help, "float64", defaultValue, false, func(value string) (interface{}, error) {
 		return strconv.ParseFloat(value, 64)
-	})
+	}, opts...)
 }
 
 // DurationL same as Duration, but without a shorthand.
-func (f *Flags) DurationL(long string, defaultValue time.Duration, help string) {
-	f.Duration("", long, defaultValue, help)
+func (f *Flags) DurationL(long string, defaultValue time.Duration, help string, opts ...FlagOption) {
+	f.Duration("", long, defaultValue, help, opts...)
 }
 
 // Duration registers a duration flag.
-func (f *Flags) Duration(short, long string, defaultValue time.Duration, help string) {
+func (f *Flags) Duration(short, long string, defaultValue time.Duration, help string, opts ...FlagOption) {
 	f.register(short, long, help, "duration", defaultValue, false, func(value string) (interface{}, error) {
 		return time.ParseDuration(value)
-	})
+	}, opts...)
+}
+
+// PathL same as Path, but without a shorthand.
+func (f *Flags) PathL(long, defaultValue, help string, po PathOptions, opts ...FlagOption) {
+	f.Path("", long, defaultValue, help, po, opts...)
+}
+
+// Path registers a file system path flag, which is completed from the file system.
+// A leading tilde is expanded to the user's home directory.
+func (f *Flags) Path(short, long, defaultValue, help string, po PathOptions, opts ...FlagOption) {
+	// Prepend the path completer, so a custom completer option takes precedence.
+	opts = append([]FlagOption{
+		FlagCompleter(PathCompleter(po)),
+		func(i *flagItem) { i.path = &po },
+	}, opts...)
+
+	f.register(short, long, help, "path", defaultValue, false, func(value string) (interface{}, error) {
+		return po.parse(trimQuotes(value), pathAny)
+	}, opts...)
 }
 
 // trimQuotes removes a single '"' rune from the start and end of s.
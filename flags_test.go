@@ -1432,3 +1432,113 @@ func TestTrimQuotes(t *testing.T) {
 		}
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Interleaved flags and positional arguments
+// ---------------------------------------------------------------------------
+
+func TestFlagParseInterleaved(t *testing.T) {
+	t.Run("flags between positional", func(t *testing.T) {
+		f := &Flags{}
+		f.Bool("f", "force", false, "help")
+		f.String("n", "name", "default", "help")
+
+		res := newFlagMap()
+		pos, rest, err := f.parseInterleaved([]string{"a", "--force", "b", "-n", "x", "c"}, res)
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+		if !reflect.DeepEqual(pos, []string{"a", "b", "c"}) {
+			t.Fatalf("expected [a b c], got %v", pos)
+		}
+		if rest != nil {
+			t.Fatalf("expected nil rest, got %v", rest)
+		}
+		if !res.Bool("force") {
+			t.Fatal("expected force=true")
+		}
+		if v := res.String("name"); v != "x" {
+			t.Fatalf("expected 'x', got '%s'", v)
+		}
+	})
+
+	t.Run("double dash", func(t *testing.T) {
+		f := &Flags{}
+		f.Bool("f", "force", false, "help")
+
+		res := newFlagMap()
+		pos, rest, err := f.parseInterleaved([]string{"a", "--", "--force", "b"}, res)
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+		if !reflect.DeepEqual(pos, []string{"a"}) {
+			t.Fatalf("expected [a], got %v", pos)
+		}
+		if !reflect.DeepEqual(rest, []string{"--force", "b"}) {
+			t.Fatalf("expected [--force b], got %v", rest)
+		}
+		if res.Bool("force") || !res["force"].IsDefault {
+			t.Fatal("expected force to be the default value")
+		}
+	})
+
+	t.Run("trailing double dash", func(t *testing.T) {
+		f := &Flags{}
+		pos, rest, err := f.parseInterleaved([]string{"a", "--"}, newFlagMap())
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+		if !reflect.DeepEqual(pos, []string{"a"}) {
+			t.Fatalf("expected [a], got %v", pos)
+		}
+		if rest == nil || len(rest) != 0 {
+			t.Fatalf("expected empty non-nil rest, got %#v", rest)
+		}
+	})
+
+	t.Run("negative numbers after positional", func(t *testing.T) {
+		f := &Flags{}
+		f.Bool("f", "force", false, "help")
+
+		res := newFlagMap()
+		pos, _, err := f.parseInterleaved([]string{"1", "-5", "-f", "-1.5"}, res)
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+		if !reflect.DeepEqual(pos, []string{"1", "-5", "-1.5"}) {
+			t.Fatalf("expected [1 -5 -1.5], got %v", pos)
+		}
+		if !res.Bool("force") {
+			t.Fatal("expected force=true")
+		}
+	})
+
+	t.Run("invalid flag after positional", func(t *testing.T) {
+		f := &Flags{}
+		_, _, err := f.parseInterleaved([]string{"a", "--unknown"}, newFlagMap())
+		if err == nil {
+			t.Fatal("expected parse error, got nil")
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// Leading flags do not consume the double dash
+// ---------------------------------------------------------------------------
+
+func TestFlagParseLeading(t *testing.T) {
+	f := &Flags{}
+	f.Bool("f", "force", false, "help")
+
+	res := newFlagMap()
+	left, err := f.parseLeading([]string{"-f", "--", "a"}, res)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if !reflect.DeepEqual(left, []string{"--", "a"}) {
+		t.Fatalf("expected [-- a], got %v", left)
+	}
+	if !res.Bool("force") {
+		t.Fatal("expected force=true")
+	}
+}
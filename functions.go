@@ -25,11 +25,7 @@
 package grumble
 
 import (
-	"fmt"
 	"os"
-	"sort"
-
-	"github.com/desertbit/columnize"
 )
 
 func defaultInterruptHandler(a *App, count int) {
@@ -41,280 +37,14 @@ func defaultInterruptHandler(a *App, count int) {
 }
 
 func defaultPrintHelp(a *App, shell bool) {
-	// Columnize options.
-	config := columnize.DefaultConfig()
-	config.Delim = "|"
-	config.Glue = "  "
-	config.Prefix = "  "
-
 	// ASCII logo.
 	if a.printASCIILogo != nil {
 		a.printASCIILogo(a)
 	}
 
-	// Description.
-	if (len(a.config.Description)) > 0 {
-		a.Printf("\n%s\n", a.config.Description)
-	}
-
-	// Usage.
-	if !shell {
-		a.Println()
-		printHeadline(a, "Usage:")
-		a.Printf("  %s [command]\n", a.config.Name)
-	}
-
-	// Group the commands by their help group if present.
-	groups := make(map[string]*Commands)
-	for _, c := range a.commands.list {
-		key := c.HelpGroup
-		if len(key) == 0 {
-			key = "Commands:"
-		}
-		cc := groups[key]
-		if cc == nil {
-			cc = new(Commands)
-			groups[key] = cc
-		}
-		cc.Add(c)
-	}
-
-	// Sort the map by the keys.
-	var keys []string
-	for k := range groups {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	// Print each commands group.
-	for _, headline := range keys {
-		cc := groups[headline]
-		cc.Sort()
-
-		var output []string
-		for _, c := range cc.list {
-			name := c.Name
-			for _, a := range c.Aliases {
-				name += ", " + a
-			}
-			output = append(output, fmt.Sprintf("%s | %v", name, c.Help))
-		}
-
-		if len(output) > 0 {
-			a.Println()
-			printHeadline(a, headline)
-			a.Printf("%s\n", columnize.Format(output, config))
-		}
-	}
-
-	// Sub Commands.
-	if a.config.HelpSubCommands {
-		// Check if there is at least one sub command.
-		hasSubCmds := false
-		for _, c := range a.commands.list {
-			if len(c.commands.list) > 0 {
-				hasSubCmds = true
-				break
-			}
-		}
-		if hasSubCmds {
-			// Headline.
-			a.Println()
-			printHeadline(a, "Sub Commands:")
-			hp := headlinePrinter(a)
-
-			// Only print the first level of sub commands.
-			for _, c := range a.commands.list {
-				if len(c.commands.list) == 0 {
-					continue
-				}
-
-				var output []string
-				for _, c := range c.commands.list {
-					name := c.Name
-					for _, a := range c.Aliases {
-						name += ", " + a
-					}
-					output = append(output, fmt.Sprintf("%s | %v", name, c.Help))
-				}
-
-				a.Println()
-				_, _ = hp(c.Name + ":")
-				a.Printf("%s\n", columnize.Format(output, config))
-			}
-		}
-	}
-
-	// Flags.
-	if !shell {
-		printFlags(a, &a.flags)
-	}
-
-	a.Println()
+	a.renderHelp(a.helpTemplate, a.HelpData(nil, shell))
 }
 
 func defaultPrintCommandHelp(a *App, cmd *Command, shell bool) {
-	// Columnize options.
-	config := columnize.DefaultConfig()
-	config.Delim = "|"
-	config.Glue = "  "
-	config.Prefix = "  "
-
-	// Help description.
-	if len(cmd.LongHelp) > 0 {
-		a.Printf("\n%s\n", cmd.LongHelp)
-	} else {
-		a.Printf("\n%s\n", cmd.Help)
-	}
-
-	// Usage.
-	printUsage(a, cmd)
-
-	// Arguments.
-	printArgs(a, &cmd.args)
-
-	// Flags.
-	printFlags(a, &cmd.flags)
-
-	// Sub Commands.
-	if len(cmd.commands.list) > 0 {
-		// Only print the first level of sub commands.
-		var output []string
-		for _, c := range cmd.commands.list {
-			name := c.Name
-			for _, a := range c.Aliases {
-				name += ", " + a
-			}
-			output = append(output, fmt.Sprintf("%s | %v", name, c.Help))
-		}
-
-		a.Println()
-		printHeadline(a, "Sub Commands:")
-		a.Printf("%s\n", columnize.Format(output, config))
-	}
-
-	a.Println()
-}
-
-func headlinePrinter(a *App) func(v ...interface{}) (int, error) {
-	if a.config.NoColor || a.config.HelpHeadlineColor == nil {
-		return a.Println
-	}
-	return func(v ...interface{}) (int, error) {
-		return a.config.HelpHeadlineColor.Fprintln(a, v...)
-	}
-}
-
-func printHeadline(a *App, s string) {
-	hp := headlinePrinter(a)
-	if a.config.HelpHeadlineUnderline {
-		_, _ = hp(s)
-		u := ""
-		for i := 0; i < len(s); i++ {
-			u += "="
-		}
-		_, _ = hp(u)
-	} else {
-		_, _ = hp(s)
-	}
-}
-
-func printUsage(a *App, cmd *Command) {
-	a.Println()
-	printHeadline(a, "Usage:")
-
-	// Print either the user-provided usage message or compose
-	// one on our own from the flags and args.
-	if len(cmd.Usage) > 0 {
-		a.Printf("  %s\n", cmd.Usage)
-		return
-	}
-
-	// Layout: Cmd [Flags] Args
-	a.Printf("  %s", cmd.Name)
-	if !cmd.flags.empty() {
-		a.Printf(" [flags]")
-	}
-	if !cmd.args.empty() {
-		for _, arg := range cmd.args.list {
-			name := arg.Name
-			if arg.isList {
-				name += "..."
-			}
-
-			if arg.optional {
-				a.Printf(" [%s]", name)
-			} else {
-				a.Printf(" %s", name)
-			}
-
-			if arg.isList && (arg.listMin != -1 || arg.listMax != -1) {
-				a.Printf("{")
-				if arg.listMin != -1 {
-					a.Printf("%d", arg.listMin)
-				}
-				a.Printf(",")
-				if arg.listMax != -1 {
-					a.Printf("%d", arg.listMax)
-				}
-				a.Printf("}")
-			}
-		}
-	}
-	a.Println()
-}
-
-func printArgs(a *App, args *Args) {
-	// Columnize options.
-	config := columnize.DefaultConfig()
-	config.Delim = "|"
-	config.Glue = " "
-	config.Prefix = "  "
-
-	var output []string
-	for _, a := range args.list {
-		defaultValue := ""
-		if a.Default != nil && len(fmt.Sprintf("%v", a.Default)) > 0 && a.optional {
-			defaultValue = fmt.Sprintf("(default: %v)", a.Default)
-		}
-		output = append(output, fmt.Sprintf("%s || %s |||| %s %s", a.Name, a.HelpArgs, a.Help, defaultValue))
-	}
-
-	if len(output) > 0 {
-		a.Println()
-		printHeadline(a, "Args:")
-		a.Printf("%s\n", columnize.Format(output, config))
-	}
-}
-
-func printFlags(a *App, flags *Flags) {
-	// Columnize options.
-	config := columnize.DefaultConfig()
-	config.Delim = "|"
-	config.Glue = " "
-	config.Prefix = "  "
-
-	flags.sort()
-
-	var output []string
-	for _, fi := range flags.list {
-		long := "--" + fi.Long
-		short := ""
-		if len(fi.Short) > 0 {
-			short = "-" + fi.Short + ","
-		}
-
-		defaultValue := ""
-		if fi.showDefault() && len(fmt.Sprintf("%v", fi.Default)) > 0 {
-			defaultValue = fmt.Sprintf("(default: %v)", fi.Default)
-		}
-
-		output = append(output, fmt.Sprintf("%s | %s | %s |||| %s %s", short, long, fi.HelpArgs, fi.Help, defaultValue))
-	}
-
-	if len(output) > 0 {
-		a.Println()
-		printHeadline(a, "Flags:")
-		a.Printf("%s\n", columnize.Format(output, config))
-	}
+	a.renderHelp(a.commandHelpTemplate, a.HelpData(cmd, shell))
 }
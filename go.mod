@@ -8,16 +8,11 @@ require (
 	github.com/desertbit/go-shlex v0.1.1
 	github.com/desertbit/readline v1.5.1
 	github.com/fatih/color v1.19.0
-	gopkg.in/AlecAivazis/survey.v1 v1.8.8
+	golang.org/x/term v0.41.0
 )
 
 require (
-	github.com/Netflix/go-expect v0.0.0-20190729225929-0e00d9168667 // indirect
-	github.com/hinshun/vt10x v0.0.0-20180809195222-d55458df857c // indirect
-	github.com/kballard/go-shellquote v0.0.0-20180428030007-95032a82bc51 // indirect
-	github.com/kr/pty v1.1.8 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	github.com/mgutz/ansi v0.0.0-20200706080929-d51e80ef957d // indirect
 	golang.org/x/sys v0.42.0 // indirect
 )
@@ -26,16 +26,21 @@
 package grumble
 
 import (
+	"errors"
 	"fmt"
 	"os"
 )
 
 // Main is a shorthand to run the app within the main function.
 // This function will handle the error and exit the application on error.
+// The exit code is determined by ExitCode.
 func Main(a *App) {
 	err := a.Run()
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+		var ee *ExitError
+		if !errors.As(err, &ee) || ee.Err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		os.Exit(ExitCode(err))
 	}
 }
@@ -0,0 +1,576 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/desertbit/columnize"
+	"github.com/desertbit/readline"
+)
+
+// DefaultHelpTemplate is the default template of the app help.
+const DefaultHelpTemplate = `
+{{- if .Description}}
+{{wrap .Description}}
+{{end}}
+{{- if not .Shell}}
+{{headline "Usage:"}}
+  {{.Usage}}
+{{end}}
+{{- range .Groups}}
+{{headline .Name}}
+{{commands .Commands}}
+{{end}}
+{{- if .SubCommandGroups}}
+{{headline "Sub Commands:"}}
+{{- range .SubCommandGroups}}
+
+{{subheadline .Name}}:
+{{commands .Commands}}
+{{- end}}
+{{end}}
+{{- if and (not .Shell) .Flags}}
+{{headline "Flags:"}}
+{{flags .Flags}}
+{{end}}
+`
+
+// DefaultCommandHelpTemplate is the default template of the command help.
+const DefaultCommandHelpTemplate = `
+{{if .Command.LongHelp}}{{wrap .Command.LongHelp}}{{else}}{{wrap .Command.Help}}{{end}}
+{{- if .Command.Deprecated}}
+
+Deprecated: {{wrap .Command.Deprecated}}
+{{- end}}
+
+{{headline "Usage:"}}
+  {{.Usage}}
+{{- if .Args}}
+
+{{headline "Args:"}}
+{{args .Args}}
+{{- end}}
+{{- if .Flags}}
+
+{{headline "Flags:"}}
+{{flags .Flags}}
+{{- end}}
+{{- if .SubCommands}}
+
+{{headline "Sub Commands:"}}
+{{commands .SubCommands}}
+{{- end}}
+{{- if .Examples}}
+
+{{headline "Examples:"}}
+{{indent 2 .Examples}}
+{{- end}}
+{{- if .SeeAlso}}
+
+{{headline "See Also:"}}
+{{commands .SeeAlso}}
+{{- end}}
+
+`
+
+// HelpData is passed to the help templates.
+type HelpData struct {
+	// Name and Description of the app.
+	Name        string
+	Description string
+
+	// Shell is true, if the help is printed within the interactive shell.
+	Shell bool
+
+	// Path contains the names from the top-level command down to the
+	// described command. It is empty for the app help.
+	Path []string
+
+	// Width is the width the help is wrapped to. It is 0, if the help
+	// should not be wrapped.
+	Width int
+
+	// Command is the described command. It is nil for the app help.
+	Command *HelpCommand
+
+	// Usage line of the app or command.
+	Usage string
+
+	// Groups contains the top-level commands grouped by their help group.
+	// Only set for the app help.
+	Groups []HelpGroup
+
+	// SubCommandGroups contains the first level of sub commands grouped by
+	// their parent command. Only set for the app help, if enabled by
+	// Config.HelpSubCommands.
+	SubCommandGroups []HelpGroup
+
+	// SubCommands contains the first level of sub commands of the command.
+	SubCommands []HelpCommand
+
+	// Flags of the command or the app.
+	Flags []HelpFlag
+
+	// Args of the command.
+	Args []HelpArg
+
+	// Examples of the command usage.
+	Examples string
+
+	// SeeAlso contains the related commands. Their name is the
+	// command path as defined by Command.SeeAlso.
+	SeeAlso []HelpCommand
+}
+
+// HelpGroup is a named group of commands.
+type HelpGroup struct {
+	Name     string
+	Commands []HelpCommand
+}
+
+// HelpCommand describes a command.
+type HelpCommand struct {
+	Name     string
+	Aliases  []string
+	Help     string
+	LongHelp string
+
+	// Abbreviation is the shortest unique prefix of the name,
+	// if command abbreviations are enabled.
+	Abbreviation string
+
+	// Deprecated is the deprecation message of a deprecated command.
+	Deprecated string
+}
+
+// HelpFlag describes a flag.
+type HelpFlag struct {
+	Short    string
+	Long     string
+	HelpArgs string
+	Help     string
+
+	// Default is the formatted default value. It is empty,
+	// if no default value should be shown.
+	Default string
+
+	// Required is true, if the flag must be set.
+	Required bool
+}
+
+// HelpArg describes an argument.
+type HelpArg struct {
+	Name     string
+	HelpArgs string
+	Help     string
+	Optional bool
+	List     bool
+
+	// Default is the formatted default value. It is empty,
+	// if no default value should be shown.
+	Default string
+}
+
+// parseHelpTemplate parses the help template text.
+func (a *App) parseHelpTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(a.helpFuncs()).Parse(text)
+}
+
+// helpFuncs returns the functions available within the help templates.
+// The text is wrapped to the width of the terminal.
+func (a *App) helpFuncs() template.FuncMap {
+	return template.FuncMap{
+		"headline":    a.headline,
+		"subheadline": a.subheadline,
+		"commands": func(cmds []HelpCommand) string {
+			return formatHelpCommands(cmds, a.helpWidth())
+		},
+		"flags": func(flags []HelpFlag) string {
+			return formatHelpFlags(flags, a.helpWidth())
+		},
+		"args": func(args []HelpArg) string {
+			return formatHelpArgs(args, a.helpWidth())
+		},
+		"wrap": func(s string) string {
+			return wrapText(s, a.helpWidth())
+		},
+		"indent": indent,
+		"join":   strings.Join,
+	}
+}
+
+// helpWidth returns the width the help is wrapped to.
+// Returns 0, if the help should not be wrapped.
+func (a *App) helpWidth() int {
+	switch {
+	case a.config.HelpWidth > 0:
+		return a.config.HelpWidth
+	case a.config.HelpWidth < 0:
+		return 0
+	case a.rl != nil:
+		return max(a.rl.Config.FuncGetWidth(), 0)
+	default:
+		return max(readline.GetScreenWidth(), 0)
+	}
+}
+
+// renderHelp executes the help template and writes the result to the app.
+func (a *App) renderHelp(t *template.Template, data *HelpData) {
+	var buf bytes.Buffer
+	err := t.Execute(&buf, data)
+	if err != nil {
+		a.PrintError(fmt.Errorf("help template: %v", err))
+		return
+	}
+	p := a.Pager()
+	_, _ = p.Write(buf.Bytes())
+	err = p.Close()
+	if err != nil {
+		a.PrintError(err)
+	}
+}
+
+// HelpData returns the data for the help templates. The app help data
+// is returned, if cmd is nil.
+func (a *App) HelpData(cmd *Command, shell bool) *HelpData {
+	d := &HelpData{
+		Name:        a.config.Name,
+		Description: a.config.Description,
+		Shell:       shell,
+		Width:       a.helpWidth(),
+	}
+
+	if cmd == nil {
+		d.Usage = a.config.Name + " [command]"
+		d.Groups = helpGroups(&a.commands)
+		if a.config.HelpSubCommands {
+			for _, c := range a.commands.list {
+				if c.hidden() {
+					continue
+				}
+				if hcs := helpCommands(&c.commands); len(hcs) > 0 {
+					d.SubCommandGroups = append(d.SubCommandGroups, HelpGroup{Name: c.Name, Commands: hcs})
+				}
+			}
+		}
+		d.Flags = helpFlags(&a.flags)
+		return d
+	}
+
+	for c := cmd; c != nil; c = c.parent {
+		d.Path = append([]string{c.Name}, d.Path...)
+	}
+	hc := newHelpCommand(&a.commands, cmd)
+	if cmd.parent != nil {
+		hc = newHelpCommand(&cmd.parent.commands, cmd)
+	}
+	d.Command = &hc
+	d.SubCommands = helpCommands(&cmd.commands)
+	d.Flags = helpFlags(&cmd.flags)
+	d.Args = helpArgs(&cmd.args)
+	d.Examples = strings.Trim(cmd.Examples, "\n")
+
+	// The usage contains the full command path. The app name
+	// is omitted within the shell.
+	path := strings.Join(d.Path, " ")
+	if !shell {
+		path = a.config.Name + " " + path
+	}
+	d.Usage = usage(cmd, path)
+
+	for _, name := range cmd.SeeAlso {
+		hc := HelpCommand{Name: name}
+		if c, _, _ := a.commands.FindCommand(strings.Fields(name)); c != nil {
+			hc.Help = c.Help
+		}
+		d.SeeAlso = append(d.SeeAlso, hc)
+	}
+	return d
+}
+
+// headline formats a help headline with the configured color and underline.
+func (a *App) headline(s string) string {
+	h := a.subheadline(s)
+	if a.config.HelpHeadlineUnderline {
+		h += "\n" + a.subheadline(strings.Repeat("=", len(s)))
+	}
+	return h
+}
+
+// subheadline formats a help headline with the configured color.
+func (a *App) subheadline(s string) string {
+	if a.config.NoColor || a.config.HelpHeadlineColor == nil {
+		return s
+	}
+	return a.config.HelpHeadlineColor.Sprint(s)
+}
+
+func newHelpCommand(cmds *Commands, c *Command) HelpCommand {
+	hc := HelpCommand{
+		Name:       c.Name,
+		Aliases:    c.Aliases,
+		Help:       c.Help,
+		LongHelp:   c.LongHelp,
+		Deprecated: c.Deprecated,
+	}
+	if abbr := cmds.Abbreviation(c); abbr != c.Name {
+		hc.Abbreviation = abbr
+	}
+	return hc
+}
+
+// helpGroups groups the commands by their help group.
+// The groups are sorted by their name and the commands within by theirs.
+func helpGroups(cmds *Commands) []HelpGroup {
+	groups := make(map[string][]*Command)
+	for _, c := range cmds.list {
+		if c.hidden() {
+			continue
+		}
+
+		key := c.HelpGroup
+		if len(key) == 0 {
+			key = "Commands:"
+		}
+		groups[key] = append(groups[key], c)
+	}
+
+	var keys []string
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	hgs := make([]HelpGroup, 0, len(keys))
+	for _, k := range keys {
+		list := groups[k]
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Name < list[j].Name
+		})
+
+		hg := HelpGroup{Name: k}
+		for _, c := range list {
+			hg.Commands = append(hg.Commands, newHelpCommand(cmds, c))
+		}
+		hgs = append(hgs, hg)
+	}
+	return hgs
+}
+
+func helpCommands(cmds *Commands) (hcs []HelpCommand) {
+	for _, c := range cmds.list {
+		if c.hidden() {
+			continue
+		}
+		hcs = append(hcs, newHelpCommand(cmds, c))
+	}
+	return
+}
+
+func helpFlags(flags *Flags) (hfs []HelpFlag) {
+	flags.sort()
+	for _, fi := range flags.list {
+		if fi.hidden() {
+			continue
+		}
+
+		hf := HelpFlag{
+			Short:    fi.Short,
+			Long:     fi.Long,
+			HelpArgs: fi.HelpArgs,
+			Help:     fi.Help,
+			Required: fi.required,
+		}
+		if fi.showDefault() {
+			hf.Default = fmt.Sprintf("%v", fi.Default)
+		}
+		hfs = append(hfs, hf)
+	}
+	return
+}
+
+func helpArgs(args *Args) (has []HelpArg) {
+	for _, ai := range args.list {
+		ha := HelpArg{
+			Name:     ai.Name,
+			HelpArgs: ai.HelpArgs,
+			Help:     ai.Help,
+			Optional: ai.optional,
+			List:     ai.isList,
+		}
+		if ai.Default != nil && ai.optional {
+			ha.Default = fmt.Sprintf("%v", ai.Default)
+		}
+		has = append(has, ha)
+	}
+	return
+}
+
+// usage returns the user-provided usage message of the command or
+// composes one from the command path, flags and args.
+func usage(cmd *Command, path string) string {
+	if len(cmd.Usage) > 0 {
+		return cmd.Usage
+	}
+
+	// Layout: Path [Flags] Args
+	s := path
+	if !cmd.flags.empty() {
+		s += " [flags]"
+	}
+	for _, arg := range cmd.args.list {
+		name := arg.Name
+		if arg.isList {
+			name += "..."
+		}
+
+		if arg.optional {
+			s += " [" + name + "]"
+		} else {
+			s += " " + name
+		}
+
+		if arg.isList && (arg.listMin != -1 || arg.listMax != -1) {
+			s += "{"
+			if arg.listMin != -1 {
+				s += fmt.Sprintf("%d", arg.listMin)
+			}
+			s += ","
+			if arg.listMax != -1 {
+				s += fmt.Sprintf("%d", arg.listMax)
+			}
+			s += "}"
+		}
+	}
+	return s
+}
+
+// formatHelpCommands formats the commands to aligned columns.
+func formatHelpCommands(cmds []HelpCommand, width int) string {
+	config := columnize.DefaultConfig()
+	config.Delim = "|"
+	config.Glue = "  "
+	config.Prefix = "  "
+
+	rows := make([]string, 0, len(cmds))
+	descs := make([]string, 0, len(cmds))
+	for _, c := range cmds {
+		name := c.Name
+		if len(c.Abbreviation) > 0 {
+			name += " (" + c.Abbreviation + ")"
+		}
+		for _, a := range c.Aliases {
+			name += ", " + a
+		}
+		rows = append(rows, fmt.Sprintf("%s | %s", name, descMarker))
+		descs = append(descs, c.Help)
+	}
+	return formatColumns(rows, descs, config, width)
+}
+
+// formatHelpFlags formats the flags to aligned columns.
+func formatHelpFlags(flags []HelpFlag, width int) string {
+	config := columnize.DefaultConfig()
+	config.Delim = "|"
+	config.Glue = " "
+	config.Prefix = "  "
+
+	rows := make([]string, 0, len(flags))
+	descs := make([]string, 0, len(flags))
+	for _, f := range flags {
+		long := "--" + f.Long
+		short := ""
+		if len(f.Short) > 0 {
+			short = "-" + f.Short + ","
+		}
+
+		desc := f.Help
+		if f.Required {
+			desc += " (required)"
+		} else if len(f.Default) > 0 {
+			desc += fmt.Sprintf(" (default: %s)", f.Default)
+		}
+
+		rows = append(rows, fmt.Sprintf("%s | %s | %s |||| %s", short, long, f.HelpArgs, descMarker))
+		descs = append(descs, desc)
+	}
+	return formatColumns(rows, descs, config, width)
+}
+
+// formatHelpArgs formats the args to aligned columns.
+func formatHelpArgs(args []HelpArg, width int) string {
+	config := columnize.DefaultConfig()
+	config.Delim = "|"
+	config.Glue = " "
+	config.Prefix = "  "
+
+	rows := make([]string, 0, len(args))
+	descs := make([]string, 0, len(args))
+	for _, a := range args {
+		desc := a.Help
+		if len(a.Default) > 0 {
+			desc += fmt.Sprintf(" (default: %s)", a.Default)
+		}
+
+		rows = append(rows, fmt.Sprintf("%s || %s |||| %s", a.Name, a.HelpArgs, descMarker))
+		descs = append(descs, desc)
+	}
+	return formatColumns(rows, descs, config, width)
+}
+
+// descMarker marks the description column of a row passed to formatColumns.
+const descMarker = "\x00"
+
+// formatColumns aligns the rows to columns and replaces the description marker
+// of each row with its description. The descriptions are wrapped to the width
+// with a hanging indent to the description column.
+func formatColumns(rows, descs []string, config *columnize.Config, width int) string {
+	lines := strings.Split(columnize.Format(rows, config), "\n")
+	for i, l := range lines {
+		pos := strings.Index(l, descMarker)
+		if pos < 0 || i >= len(descs) {
+			continue
+		}
+		lines[i] = l[:pos] + hangText(descs[i], textWidth(l[:pos]), width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// indent prefixes each non-empty line of the text with n spaces.
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if len(l) > 0 {
+			lines[i] = pad + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
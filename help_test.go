@@ -0,0 +1,272 @@
+package grumble
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// captureStdout returns everything written to os.Stdout by f.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	return captureFile(t, &os.Stdout, f)
+}
+
+// captureStderr returns everything written to os.Stderr by f.
+func captureStderr(t *testing.T, f func()) string {
+	t.Helper()
+	return captureFile(t, &os.Stderr, f)
+}
+
+// captureFile replaces the file with a pipe while f runs
+// and returns everything written to it.
+func captureFile(t *testing.T, file **os.File, f func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orig := *file
+	*file = w
+	defer func() { *file = orig }()
+
+	done := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		done <- string(b)
+	}()
+
+	f()
+	w.Close()
+	return <-done
+}
+
+// ---------------------------------------------------------------------------
+// TestHelpDefaultTemplate
+// ---------------------------------------------------------------------------
+
+func TestHelpDefaultTemplate(t *testing.T) {
+	admin := &Command{Name: "admin", Help: "admin tools", Aliases: []string{"adm"}}
+	admin.AddCommand(&Command{Name: "users", Help: "list users", Run: func(c *Context) error { return nil }})
+
+	a := newTestApp(t, &Config{Name: "app", Description: "the app description", NoColor: true}, admin, &Command{
+		Name:      "deploy",
+		Help:      "deploy an app",
+		LongHelp:  "deploy an app to the cluster",
+		HelpGroup: "Cluster:",
+		Flags: func(f *Flags) {
+			f.Bool("f", "force", false, "force it")
+			f.IntL("replicas", 1, "number of replicas")
+		},
+		Args: func(a *Args) {
+			a.String("name", "the app name")
+			a.StringList("hosts", "the hosts", Default([]string{"a"}), Max(3))
+		},
+		Run: func(c *Context) error { return nil },
+	})
+
+	got := captureStdout(t, func() { a.printHelp(a, false) })
+	want := `
+the app description
+
+Usage:
+  app [command]
+
+Cluster:
+  deploy  deploy an app
+
+Commands:
+  admin, adm  admin tools
+
+Flags:
+  -h, --help    bool    display help
+      --nocolor bool    disable color output
+      --norc    bool    do not run the rc file
+
+`
+	if got != want {
+		t.Errorf("unexpected app help:\n%q\nwant:\n%q", got, want)
+	}
+
+	got = captureStdout(t, func() { a.printCommandHelp(a, a.commands.Get("deploy"), false) })
+	want = `
+deploy an app to the cluster
+
+Usage:
+  app deploy [flags] name [hosts...]{,3}
+
+Args:
+  name   string         the app name
+  hosts  string list    the hosts (default: [a])
+
+Flags:
+  -f, --force    bool    force it
+  -h, --help     bool    display help
+      --replicas int     number of replicas (default: 1)
+
+`
+	if got != want {
+		t.Errorf("unexpected command help:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestHelpCustomTemplate
+// ---------------------------------------------------------------------------
+
+func TestHelpCustomTemplate(t *testing.T) {
+	admin := &Command{Name: "admin", Help: "admin tools", Aliases: []string{"adm"}}
+	admin.AddCommand(&Command{Name: "users", Help: "list users", Run: func(c *Context) error { return nil }})
+
+	a := newTestApp(t, &Config{
+		Name:                "app",
+		NoColor:             true,
+		HelpTemplate:        `{{range .Groups}}{{range .Commands}}{{.Name}};{{end}}{{end}}`,
+		CommandHelpTemplate: `{{join .Path " "}}|{{.Usage}}|{{range .Args}}{{.Name}}={{.Default}};{{end}}`,
+	}, admin, &Command{
+		Name:      "deploy",
+		Help:      "deploy an app",
+		HelpGroup: "Cluster:",
+		Run:       func(c *Context) error { return nil },
+	})
+
+	got := captureStdout(t, func() { a.printHelp(a, true) })
+	if got != "deploy;admin;" {
+		t.Errorf("unexpected app help %q", got)
+	}
+
+	users := a.commands.Get("admin").commands.Get("users")
+	got = captureStdout(t, func() { a.printCommandHelp(a, users, true) })
+	if got != "admin users|admin users [flags]|" {
+		t.Errorf("unexpected command help %q", got)
+	}
+
+	assertPanics(t, "invalid help template", func() {
+		New(&Config{Name: "app", HelpTemplate: "{{.Invalid"})
+	})
+}
+
+// ---------------------------------------------------------------------------
+// TestHelpData
+// ---------------------------------------------------------------------------
+
+func TestHelpData(t *testing.T) {
+	admin := &Command{Name: "admin", Help: "admin tools", Aliases: []string{"adm"}}
+	admin.AddCommand(&Command{Name: "users", Help: "list users", Run: func(c *Context) error { return nil }})
+
+	a := newTestApp(t, &Config{
+		Name:                 "app",
+		NoColor:              true,
+		HelpSubCommands:      true,
+		CommandAbbreviations: true,
+	}, admin, &Command{
+		Name:      "deploy",
+		Help:      "deploy an app",
+		LongHelp:  "deploy an app to the cluster",
+		HelpGroup: "Cluster:",
+		Flags: func(f *Flags) {
+			f.Bool("f", "force", false, "force it")
+			f.IntL("replicas", 1, "number of replicas")
+		},
+		Args: func(a *Args) {
+			a.String("name", "the app name")
+			a.StringList("hosts", "the hosts", Default([]string{"a"}), Max(3))
+		},
+		Run: func(c *Context) error { return nil },
+	})
+
+	d := a.HelpData(nil, false)
+	if d.Command != nil || len(d.Path) != 0 || d.Usage != "app [command]" {
+		t.Errorf("unexpected app help data %+v", d)
+	}
+
+	var groups []string
+	for _, g := range d.Groups {
+		groups = append(groups, g.Name)
+	}
+	if !reflect.DeepEqual(groups, []string{"Cluster:", "Commands:"}) {
+		t.Errorf("unexpected groups %v", groups)
+	}
+	if len(d.SubCommandGroups) != 1 || d.SubCommandGroups[0].Name != "admin" ||
+		d.SubCommandGroups[0].Commands[0].Name != "users" {
+		t.Errorf("unexpected sub command groups %+v", d.SubCommandGroups)
+	}
+	if c := d.Groups[1].Commands[0]; c.Abbreviation != "a" || !reflect.DeepEqual(c.Aliases, []string{"adm"}) {
+		t.Errorf("unexpected command %+v", c)
+	}
+
+	d = a.HelpData(a.commands.Get("deploy"), true)
+	if d.Command == nil || d.Command.Name != "deploy" || !d.Shell {
+		t.Fatalf("unexpected command help data %+v", d)
+	}
+	if len(d.Args) != 2 || d.Args[0].Default != "" || d.Args[1].Default != "[a]" || !d.Args[1].List || !d.Args[1].Optional {
+		t.Errorf("unexpected args %+v", d.Args)
+	}
+
+	var flags []string
+	for _, f := range d.Flags {
+		flags = append(flags, f.Short+"/"+f.Long+"="+f.Default)
+	}
+	if strings.Join(flags, " ") != "f/force= h/help= /replicas=1" {
+		t.Errorf("unexpected flags %v", flags)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestHelpWrapAndSections
+// ---------------------------------------------------------------------------
+
+func TestHelpWrapAndSections(t *testing.T) {
+	admin := &Command{Name: "admin", Help: "admin tools", Aliases: []string{"adm"}}
+	admin.AddCommand(&Command{Name: "users", Help: "list users", Run: func(c *Context) error { return nil }})
+
+	a := newTestApp(t, &Config{Name: "app", NoColor: true, HelpWidth: 42}, admin, &Command{
+		Name: "deploy",
+		Help: "deploy an app",
+		Run:  func(c *Context) error { return nil },
+	}, &Command{
+		Name:     "scale",
+		Help:     "scale an app",
+		LongHelp: "scale an app to the given number of replicas and wait for all of them",
+		Examples: `
+app scale web 3
+app scale --wait web 5
+`,
+		SeeAlso: []string{"deploy", "admin users"},
+		Flags: func(f *Flags) {
+			f.BoolL("wait", false, "wait until all replicas are ready and healthy")
+		},
+		Run: func(c *Context) error { return nil },
+	})
+
+	got := captureStdout(t, func() { a.printCommandHelp(a, a.commands.Get("scale"), true) })
+	want := `
+scale an app to the given number of
+replicas and wait for all of them
+
+Usage:
+  scale [flags]
+
+Flags:
+  -h, --help bool    display help
+      --wait bool    wait until all
+                     replicas are ready
+                     and healthy
+
+Examples:
+  app scale web 3
+  app scale --wait web 5
+
+See Also:
+  deploy       deploy an app
+  admin users  list users
+
+`
+	if got != want {
+		t.Errorf("unexpected command help:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/fatih/color"
+)
+
+// highlighter implements the readline.Painter interface and
+// highlights the input line as it is typed.
+type highlighter struct {
+	a *App
+}
+
+// Paint returns the line with color escape sequences.
+func (h highlighter) Paint(line []rune, _ int) []rune {
+	if h.a.config.NoColor {
+		return line
+	}
+	return []rune(h.a.highlight(line))
+}
+
+// token is a word of the input line.
+type token struct {
+	start, end int    // Position of the raw word in the line.
+	word       string // Word without quotes and escapes.
+}
+
+// highlight colors the commands, flags, quoted strings and variables of the line.
+// Unknown commands and invalid flags are colored as errors. The command path
+// is determined like on execution.
+func (a *App) highlight(line []rune) string {
+	tokens := splitTokens(line)
+
+	// The arguments of aliases are not known before the expansion.
+	if len(tokens) > 0 {
+		if _, ok := a.aliases[tokens[0].word]; ok {
+			t := tokens[0]
+			return string(line[:t.start]) + a.colorize(a.config.CommandColor, string(line[t.start:t.end])) + string(line[t.end:])
+		}
+	}
+
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.word
+	}
+	cmds, _, _, _, _ := a.commands.parse(words, nil, true)
+
+	var (
+		b          strings.Builder
+		cmd        *Command
+		last       int
+		level      int
+		valueNext  bool
+		positional bool
+		dash       bool
+		unknown    bool
+	)
+	for _, t := range tokens {
+		raw := string(line[t.start:t.end])
+
+		var c *color.Color
+		switch {
+		case unknown:
+		case valueNext:
+			valueNext = false
+			c = a.valueColor(raw)
+		case dash:
+			c = a.valueColor(raw)
+		case t.word == "--" && cmd != nil:
+			dash = true
+		case strings.HasPrefix(t.word, "-") && len(t.word) > 1:
+			name, _, joined := strings.Cut(t.word, "=")
+			var fi *flagItem
+			if cmd != nil {
+				fi = cmd.flags.find(name)
+			}
+			if fi == nil {
+				c = a.config.ErrorColor
+			} else {
+				c = a.config.FlagColor
+				valueNext = !joined && !fi.allowEmptyValue
+			}
+		case level < len(cmds) && !positional:
+			cmd = cmds[level]
+			level++
+			c = a.config.CommandColor
+		case cmd == nil || (!positional && cmd.Run == nil && len(cmd.commands.list) > 0):
+			c = a.config.ErrorColor
+			unknown = true
+		default:
+			positional = true
+			c = a.valueColor(raw)
+		}
+
+		b.WriteString(string(line[last:t.start]))
+		b.WriteString(a.colorize(c, raw))
+		last = t.end
+	}
+	b.WriteString(string(line[last:]))
+	return b.String()
+}
+
+// valueColor returns the color of an argument or flag value.
+func (a *App) valueColor(raw string) *color.Color {
+	switch {
+	case strings.HasPrefix(raw, "$"):
+		return a.config.VariableColor
+	case strings.ContainsAny(raw, `"'`):
+		return a.config.StringColor
+	default:
+		return nil
+	}
+}
+
+// splitTokens splits the line into words like the shell.
+// Quotes and escapes are removed from the words. An open quote
+// is part of the last word.
+func splitTokens(line []rune) (tokens []token) {
+	var (
+		cur     *token
+		word    strings.Builder
+		quote   rune
+		escaped bool
+	)
+	for i, r := range line {
+		if cur == nil {
+			if unicode.IsSpace(r) {
+				continue
+			}
+			cur = &token{start: i}
+			word.Reset()
+		}
+
+		switch {
+		case escaped:
+			escaped = false
+			word.WriteRune(r)
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case unicode.IsSpace(r):
+			cur.end, cur.word = i, word.String()
+			tokens = append(tokens, *cur)
+			cur = nil
+		default:
+			word.WriteRune(r)
+		}
+	}
+	if cur != nil {
+		cur.end, cur.word = len(line), word.String()
+		tokens = append(tokens, *cur)
+	}
+	return
+}
@@ -0,0 +1,93 @@
+package grumble
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// ---------------------------------------------------------------------------
+// TestHighlight
+// ---------------------------------------------------------------------------
+
+func TestHighlight(t *testing.T) {
+	newColor := func(a color.Attribute) *color.Color {
+		c := color.New(a)
+		c.EnableColor()
+		return c
+	}
+	var (
+		cmd = newColor(color.FgGreen)
+		flg = newColor(color.FgCyan)
+		str = newColor(color.FgYellow)
+		vr  = newColor(color.FgMagenta)
+		er  = newColor(color.FgRed)
+	)
+
+	a := New(&Config{
+		Name:          "app",
+		CommandColor:  cmd,
+		FlagColor:     flg,
+		StringColor:   str,
+		VariableColor: vr,
+		ErrorColor:    er,
+	})
+	a.AddCommand(&Command{
+		Name: "get",
+		Help: "get a pod",
+		Flags: func(f *Flags) {
+			f.String("n", "namespace", "default", "the namespace")
+			f.Bool("w", "wide", false, "wide output")
+		},
+		Args: func(a *Args) {
+			a.StringList("pods", "the pods")
+		},
+		Run: func(c *Context) error { return nil },
+	})
+	admin := &Command{Name: "admin", Help: "admin tools"}
+	a.AddCommand(admin)
+	admin.AddCommand(&Command{Name: "users", Help: "list users", Run: func(c *Context) error { return nil }})
+
+	tests := []struct {
+		line string
+		want string
+	}{
+		{line: "get", want: cmd.Sprint("get")},
+		{line: "  get  web ", want: "  " + cmd.Sprint("get") + "  web "},
+		{line: "gte web", want: er.Sprint("gte") + " web"},
+		{line: "get -n prod -w web", want: cmd.Sprint("get") + " " + flg.Sprint("-n") + " prod " + flg.Sprint("-w") + " web"},
+		{line: "get --namespace=prod --nope", want: cmd.Sprint("get") + " " + flg.Sprint("--namespace=prod") + " " + er.Sprint("--nope")},
+		{line: `get "my pod" $POD 'open`, want: cmd.Sprint("get") + " " + str.Sprint(`"my pod"`) + " " + vr.Sprint("$POD") + " " + str.Sprint("'open")},
+		{line: "get -- -w", want: cmd.Sprint("get") + " -- -w"},
+		{line: "admin users", want: cmd.Sprint("admin") + " " + cmd.Sprint("users")},
+		{line: "admin usr x", want: cmd.Sprint("admin") + " " + er.Sprint("usr") + " x"},
+	}
+	for _, tt := range tests {
+		if got := string(highlighter{a: a}.Paint([]rune(tt.line), 0)); got != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.line, tt.want, got)
+		}
+	}
+
+	a.config.NoColor = true
+	if got := string(highlighter{a: a}.Paint([]rune("gte web"), 0)); got != "gte web" {
+		t.Errorf("expected no colors, got %q", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestSplitTokens
+// ---------------------------------------------------------------------------
+
+func TestSplitTokens(t *testing.T) {
+	got := splitTokens([]rune(` a "b c"  d\ e 'f`))
+	want := []token{
+		{start: 1, end: 2, word: "a"},
+		{start: 3, end: 8, word: "b c"},
+		{start: 10, end: 14, word: "d e"},
+		{start: 15, end: 17, word: "f"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
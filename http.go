@@ -0,0 +1,355 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// HTTPRequest is the JSON body of a command request.
+type HTTPRequest struct {
+	// Flags maps the long flag names to their values.
+	Flags map[string]interface{} `json:"flags,omitempty"`
+
+	// Args maps the argument names to their values.
+	// List arguments take an array of values.
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+// HTTPResponse is the JSON body of a command response.
+type HTTPResponse struct {
+	// Stdout and Stderr contain the captured output of the command.
+	Stdout string `json:"stdout"`
+	Stderr string `json:"stderr,omitempty"`
+
+	// Result is the structured result set with Context.SetResult.
+	Result interface{} `json:"result,omitempty"`
+
+	// Error is set, if the command failed.
+	Error *HTTPError `json:"error,omitempty"`
+}
+
+// HTTPError describes a failed request.
+type HTTPError struct {
+	Message string `json:"message"`
+
+	// Code is the exit code of the error as returned by ExitCode.
+	Code int `json:"code"`
+}
+
+type httpHandler struct {
+	app *App
+	mu  sync.Mutex
+}
+
+// HTTPHandler returns a handler, which serves the commands over HTTP.
+//
+// A GET request to the root returns the app Schema. A POST request to the
+// command path, e.g. /admin/users, runs the command with the flags and args
+// of the HTTPRequest body and responds with a HTTPResponse.
+// Builtin commands are not served.
+//
+// The output written with the Context of the command is captured and can't
+// be prompted for input. Requests are processed one at a time.
+func (a *App) HTTPHandler() http.Handler {
+	return &httpHandler{app: a}
+}
+
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+
+	if r.Method == http.MethodGet && path == "" {
+		writeJSON(w, http.StatusOK, h.app.Schema())
+		return
+	} else if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, &HTTPResponse{
+			Error: &HTTPError{Message: "method not allowed", Code: ExitCodeUsage},
+		})
+		return
+	}
+
+	// Numbers are kept as their literal text and parsed by the flags and args.
+	var req HTTPRequest
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	err := dec.Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, &HTTPResponse{
+			Error: &HTTPError{Message: fmt.Sprintf("invalid request: %v", err), Code: ExitCodeUsage},
+		})
+		return
+	}
+
+	var names []string
+	if len(path) > 0 {
+		names = strings.Split(path, "/")
+	}
+
+	resp, status := h.run(names, &req)
+	writeJSON(w, status, resp)
+}
+
+// run the command and capture its output.
+func (h *httpHandler) run(names []string, req *HTTPRequest) (*HTTPResponse, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var stdout, stderr bytes.Buffer
+	result, err := h.app.runRequest(names, req, &stdout, &stderr)
+
+	resp := &HTTPResponse{
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+		Result: result,
+	}
+	if err == nil {
+		return resp, http.StatusOK
+	}
+
+	resp.Error = &HTTPError{Message: err.Error(), Code: ExitCode(err)}
+	switch {
+	case errors.Is(err, ErrUnknownCommand):
+		return resp, http.StatusNotFound
+	case resp.Error.Code == ExitCodeUsage:
+		return resp, http.StatusBadRequest
+	default:
+		return resp, http.StatusInternalServerError
+	}
+}
+
+// runRequest runs the command with the path names and the flags and args
+// of the request. The command output is written to stdout and stderr.
+// Returns the command result.
+func (a *App) runRequest(names []string, req *HTTPRequest, stdout, stderr io.Writer) (interface{}, error) {
+	// Find the command path.
+	var (
+		cmds   []*Command
+		parent *Command
+		cur    = &a.commands
+	)
+	for _, name := range names {
+		cmd, err := cur.Lookup(name)
+		if err != nil {
+			return nil, err
+		} else if cmd == nil || cmd.isBuiltin {
+			return nil, a.unknownCommandError([]string{name}, cur, parent)
+		}
+		cmds = append(cmds, cmd)
+		parent, cur = cmd, &cmd.commands
+	}
+	if parent == nil {
+		return nil, a.unknownCommandError(nil, cur, nil)
+	} else if parent.Run == nil {
+		return nil, &UsageError{Command: parent.Name, Err: fmt.Errorf("command '%s' can not be run", parent.Name)}
+	}
+	cmd := parent
+
+	fg, err := a.requestFlags(cmds, req.Flags)
+	if err != nil {
+		return nil, a.suggestFlag(err)
+	}
+	err = a.checkRequiredFlags(cmds, fg, false)
+	if err != nil {
+		return nil, err
+	}
+	args, err := requestArgs(&cmd.args, req.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Warn about the usage of deprecated commands and flags.
+	for _, c := range cmds {
+		if len(c.Deprecated) > 0 {
+			a.printWarning(stderr, fmt.Sprintf("command '%s' is deprecated, %s", c.Name, c.Deprecated))
+		}
+		a.warnDeprecatedFlags(stderr, &c.flags, fg)
+	}
+
+	ctx := newContext(a, cmd, fg, args)
+	ctx.stdout, ctx.stderr = stdout, stderr
+	err = a.confirmCommand(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer ctx.stopProgress()
+	err = cmd.Run(ctx)
+	return ctx.Result(), err
+}
+
+// requestFlags parses the request flags of the command path.
+// Like on the command line, flags of parent commands and the app are accepted.
+func (a *App) requestFlags(cmds []*Command, values map[string]interface{}) (FlagMap, error) {
+	all := []*Flags{&a.flags}
+	for _, c := range cmds {
+		all = append(all, &c.flags)
+	}
+
+	fg := make(FlagMap)
+	for name, v := range values {
+		id := name
+		if !strings.HasPrefix(id, "-") {
+			id = "--" + id
+		}
+
+		// The flags of the final command take precedence.
+		var fi *flagItem
+		for i := len(all) - 1; i >= 0 && fi == nil; i-- {
+			fi = all[i].find(id)
+		}
+		if fi == nil {
+			return nil, &FlagError{Flag: id, Err: fmt.Errorf("invalid flag: %s", id), flags: all[len(all)-1]}
+		}
+
+		var err error
+		if vs, isList := v.([]interface{}); isList {
+			v, err = requestListFlag(fi, vs)
+		} else {
+			var s string
+			s, err = requestValue(v)
+			if err == nil {
+				v, err = fi.parser(s)
+			}
+		}
+		if err != nil {
+			return nil, &FlagError{Flag: fi.Long, Err: fmt.Errorf("failed to parse flag %s: %w", fi.Long, err)}
+		}
+		fg[fi.Long] = &FlagMapItem{Value: v}
+	}
+
+	// Set the default values with the same precedence as the command line parser.
+	fg.copyMissingValues(a.flagMap, false)
+	for i := len(all) - 1; i > 0; i-- {
+		all[i].setDefaults(fg)
+	}
+	fg.copyMissingValues(a.flagMap, true)
+	a.flags.setDefaults(fg)
+
+	return fg, nil
+}
+
+// requestListFlag parses the values of a list flag.
+// Each element is parsed by the flag parser.
+func requestListFlag(fi *flagItem, values []interface{}) (interface{}, error) {
+	if _, isList := fi.Default.([]interface{}); !isList {
+		return nil, fmt.Errorf("flag requires a single value")
+	}
+
+	list := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		s, err := requestValue(v)
+		if err != nil {
+			return nil, err
+		}
+		pv, err := fi.parser(s)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, pv.([]interface{})...)
+	}
+	return list, nil
+}
+
+// requestArgs parses the request args.
+func requestArgs(args *Args, values map[string]interface{}) (ArgMap, error) {
+	for name := range values {
+		if args.find(name) == nil {
+			return nil, &ArgError{Arg: name, Err: fmt.Errorf("invalid argument '%s'", name)}
+		}
+	}
+
+	res := make(ArgMap)
+	for _, item := range args.list {
+		v, ok := values[item.Name]
+		if !ok {
+			if !item.optional {
+				return nil, &ArgError{Arg: item.Name, Err: fmt.Errorf("%w '%s'", ErrMissingArgument, item.Name)}
+			}
+			res[item.Name] = &ArgMapItem{Value: item.Default, IsDefault: true}
+			continue
+		}
+
+		var (
+			list []string
+			err  error
+		)
+		if vs, isList := v.([]interface{}); isList {
+			if !item.isList {
+				return nil, &ArgError{Arg: item.Name, Err: fmt.Errorf("argument '%s' requires a single value", item.Name)}
+			}
+			list = make([]string, len(vs))
+			for i, v := range vs {
+				if list[i], err = requestValue(v); err != nil {
+					break
+				}
+			}
+		} else {
+			var s string
+			s, err = requestValue(v)
+			list = []string{s}
+		}
+		if err != nil {
+			return nil, &ArgError{Arg: item.Name, Err: fmt.Errorf("invalid value for argument '%s': %w", item.Name, err)}
+		}
+
+		if item.isList {
+			if err = item.checkListRange(len(list)); err != nil {
+				return nil, err
+			}
+		}
+		if _, err = item.parser(list, res); err != nil {
+			return nil, &ArgError{Arg: item.Name, Err: err}
+		}
+	}
+	return res, nil
+}
+
+// requestValue converts a JSON value to its command line representation.
+func requestValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,208 @@
+package grumble
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func postJSON(t *testing.T, url, body string) (int, *HTTPResponse) {
+	t.Helper()
+
+	r, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+
+	var resp HTTPResponse
+	if err = json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return r.StatusCode, &resp
+}
+
+// ---------------------------------------------------------------------------
+// TestHTTPHandler
+// ---------------------------------------------------------------------------
+
+func TestHTTPHandler(t *testing.T) {
+	admin := &Command{Name: "admin", Help: "admin tools",
+		Flags: func(f *Flags) {
+			f.BoolL("verbose", false, "verbose output")
+		},
+	}
+	admin.AddCommand(&Command{
+		Name: "scale",
+		Help: "scale an app",
+		Flags: func(f *Flags) {
+			f.Int("r", "replicas", 1, "number of replicas")
+			f.BoolL("force", false, "force it", FlagDeprecated("it is always forced"))
+			f.StringList("t", "tags", nil, "the tags")
+		},
+		Args: func(a *Args) {
+			a.String("app", "the app name")
+			a.StringList("hosts", "the hosts")
+		},
+		Run: func(c *Context) error {
+			c.Printf("scaled %s to %d\n", c.Args.String("app"), c.Flags.Int("replicas"))
+			c.SetResult(map[string]interface{}{
+				"app":     c.Args.String("app"),
+				"hosts":   c.Args.StringList("hosts"),
+				"verbose": c.Flags.Bool("verbose"),
+				"tags":    c.Flags.StringList("tags"),
+			})
+			return nil
+		},
+	})
+	a := newTestApp(t, &Config{Name: "app", NoColor: true}, admin)
+
+	s := httptest.NewServer(a.HTTPHandler())
+	t.Cleanup(s.Close)
+
+	status, resp := postJSON(t, s.URL+"/admin/scale",
+		`{"flags": {"replicas": 3, "verbose": true, "tags": ["x", "y"]}, "args": {"app": "web", "hosts": ["a", "b"]}}`)
+	if status != http.StatusOK || resp.Error != nil {
+		t.Fatalf("unexpected response %d %+v", status, resp)
+	}
+	if resp.Stdout != "scaled web to 3\n" {
+		t.Errorf("unexpected output %q", resp.Stdout)
+	}
+	want := map[string]interface{}{"app": "web", "hosts": []interface{}{"a", "b"}, "verbose": true, "tags": []interface{}{"x", "y"}}
+	if !reflect.DeepEqual(resp.Result, want) {
+		t.Errorf("unexpected result %#v", resp.Result)
+	}
+
+	// Defaults and deprecation warnings.
+	status, resp = postJSON(t, s.URL+"/admin/scale", `{"flags": {"force": true}, "args": {"app": "api"}}`)
+	if status != http.StatusOK || resp.Stdout != "scaled api to 1\n" {
+		t.Errorf("unexpected response %d %+v", status, resp)
+	}
+	if resp.Stderr != "warning: flag '--force' is deprecated, it is always forced\n" {
+		t.Errorf("unexpected error output %q", resp.Stderr)
+	}
+}
+
+func TestHTTPHandlerErrors(t *testing.T) {
+	admin := &Command{Name: "admin", Help: "admin tools"}
+	admin.AddCommand(&Command{
+		Name: "scale",
+		Help: "scale an app",
+		Flags: func(f *Flags) {
+			f.Int("r", "replicas", 1, "number of replicas")
+		},
+		Args: func(a *Args) {
+			a.String("app", "the app name")
+			a.StringList("hosts", "the hosts", Max(2))
+		},
+		Run: func(c *Context) error {
+			if c.Args.String("app") == "fail" {
+				return &ExitError{Code: 3, Err: fmt.Errorf("scaling failed")}
+			}
+			return nil
+		},
+	})
+	a := newTestApp(t, &Config{Name: "app", NoColor: true}, admin)
+
+	s := httptest.NewServer(a.HTTPHandler())
+	t.Cleanup(s.Close)
+
+	tests := []struct {
+		path   string
+		body   string
+		status int
+		code   int
+		msg    string
+	}{
+		{"/admin/scal", `{}`, http.StatusNotFound, ExitCodeUsage, "unknown sub command 'scal' of 'admin', did you mean 'scale'?"},
+		{"/help", `{}`, http.StatusNotFound, ExitCodeUsage, "unknown command 'help', try 'help'"},
+		{"/admin", `{}`, http.StatusBadRequest, ExitCodeUsage, "command 'admin' can not be run"},
+		{"/admin/scale", `{}`, http.StatusBadRequest, ExitCodeUsage, "missing argument 'app'"},
+		{"/admin/scale", `{"args": {"app": "web", "x": 1}}`, http.StatusBadRequest, ExitCodeUsage, "invalid argument 'x'"},
+		{"/admin/scale", `{"args": {"app": ["web"]}}`, http.StatusBadRequest, ExitCodeUsage, "argument 'app' requires a single value"},
+		{"/admin/scale", `{"args": {"app": "web", "hosts": ["a", "b", "c"]}}`, http.StatusBadRequest, ExitCodeUsage, "argument 'hosts' requires at most 2 element(s)"},
+		{"/admin/scale", `{"flags": {"replica": 2}, "args": {"app": "web"}}`, http.StatusBadRequest, ExitCodeUsage, "invalid flag: --replica, did you mean '--replicas'?"},
+		{"/admin/scale", `{"flags": {"replicas": [1, 2]}, "args": {"app": "web"}}`, http.StatusBadRequest, ExitCodeUsage, "failed to parse flag replicas: flag requires a single value"},
+		{"/admin/scale", `{"flags": {"replicas": "x"}, "args": {"app": "web"}}`, http.StatusBadRequest, ExitCodeUsage, "failed to parse flag replicas: strconv.ParseInt: parsing \"x\": invalid syntax"},
+		{"/admin/scale", `{"args": {"app": "fail"}}`, http.StatusInternalServerError, 3, "scaling failed"},
+		{"/admin/scale", `{invalid`, http.StatusBadRequest, ExitCodeUsage, ""},
+	}
+
+	for _, tt := range tests {
+		status, resp := postJSON(t, s.URL+tt.path, tt.body)
+		if status != tt.status || resp.Error == nil || resp.Error.Code != tt.code {
+			t.Errorf("%s %s: unexpected response %d %+v", tt.path, tt.body, status, resp.Error)
+			continue
+		}
+		if len(tt.msg) > 0 && resp.Error.Message != tt.msg {
+			t.Errorf("%s %s: unexpected error %q, want %q", tt.path, tt.body, resp.Error.Message, tt.msg)
+		}
+	}
+}
+
+func TestHTTPHandlerSchema(t *testing.T) {
+	admin := &Command{Name: "admin", Help: "admin tools"}
+	admin.AddCommand(&Command{Name: "scale", Help: "scale an app", Run: func(c *Context) error { return nil }})
+	a := newTestApp(t, &Config{Name: "app", NoColor: true}, admin)
+
+	s := httptest.NewServer(a.HTTPHandler())
+	t.Cleanup(s.Close)
+
+	r, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+
+	var schema Schema
+	if err = json.NewDecoder(r.Body).Decode(&schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema.Name != "app" || len(schema.Commands) != 1 || schema.Commands[0].Commands[0].Name != "scale" {
+		t.Errorf("unexpected schema %+v", schema)
+	}
+
+	r, err = http.Get(s.URL + "/admin/scale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	if r.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("unexpected status %d", r.StatusCode)
+	}
+}
+
+func TestHTTPHandlerOutput(t *testing.T) {
+	a := newTestApp(t, &Config{Name: "app", NoColor: true}, &Command{
+		Name: "ask",
+		Help: "ask a question",
+		Run: func(c *Context) error {
+			c.Println("asking")
+			c.Table(Column{Header: "NAME"}).AddRow("web").Render()
+			_, err := c.Confirm("sure?", false)
+			return err
+		},
+	})
+	out := newScriptedShell(t, a, "y")
+	in := a.in
+
+	s := httptest.NewServer(a.HTTPHandler())
+	t.Cleanup(s.Close)
+
+	// The output is captured without touching the app and the
+	// request can't read the input of the shell.
+	status, resp := postJSON(t, s.URL+"/ask", `{}`)
+	if status != http.StatusInternalServerError || resp.Error == nil || resp.Error.Message != ErrNotInteractive.Error() {
+		t.Errorf("unexpected response %d %+v", status, resp.Error)
+	}
+	if resp.Stdout != "asking\nNAME\nweb\n" {
+		t.Errorf("unexpected output %q", resp.Stdout)
+	}
+	if out.Len() > 0 || a.in != in {
+		t.Errorf("unexpected app output %q", out.String())
+	}
+}
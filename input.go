@@ -0,0 +1,175 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// interactive returns true, if the user can be asked for input.
+func (a *App) interactive() bool {
+	return a.in != nil && (a.isShell || a.in.Config.FuncIsTerminal())
+}
+
+// confirmCommand asks to confirm the command of the context, if required.
+// Without interactive input, the command must be confirmed with --yes.
+func (a *App) confirmCommand(ctx *Context) error {
+	cmd := ctx.Command
+	if cmd.confirm == nil || a.config.AssumeYes || (cmd.yesFlag && ctx.Flags.Bool("yes")) {
+		return nil
+	} else if !ctx.interactive() {
+		if !cmd.yesFlag {
+			return &UsageError{Command: cmd.Name, Err: fmt.Errorf("%w: command '%s' requires confirmation", ErrNotInteractive, cmd.Name)}
+		}
+		return &UsageError{Command: cmd.Name, Err: fmt.Errorf("%w: confirm command '%s' with '--yes'", ErrNotInteractive, cmd.Name)}
+	}
+
+	var msg strings.Builder
+	err := cmd.confirm.Execute(&msg, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to execute the confirm message of command '%s': %w", cmd.Name, err)
+	}
+
+	ok, err := ctx.Confirm(msg.String(), false)
+	if err != nil {
+		return err
+	} else if !ok {
+		return ErrAborted
+	}
+	return nil
+}
+
+// Confirm asks a yes or no question. An empty input returns the default.
+func (a *App) Confirm(message string, def bool) (bool, error) {
+	prompt := message + " [y/N]: "
+	if def {
+		prompt = message + " [Y/n]: "
+	}
+
+	for {
+		value, err := a.readLine(prompt, false)
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		a.PrintError(fmt.Errorf("please answer 'y' or 'n'"))
+	}
+}
+
+// Input asks for a line of text until it is valid.
+// The input is required, unless a default is set or empty input is allowed.
+func (a *App) Input(po PromptOptions) (string, error) {
+	return a.ask(po, nil)
+}
+
+// Password asks for a secret line of text. The input is masked.
+func (a *App) Password(message string) (string, error) {
+	return a.ask(PromptOptions{Message: message, Secret: true}, nil)
+}
+
+// Select asks to choose one of the choices. A choice is selected by its listed
+// number, its value or any text matching only this choice. A filter matching
+// several choices lists them and asks again. An empty input selects the default.
+func (a *App) Select(message string, choices []string, def string) (string, error) {
+	if len(choices) == 0 {
+		return "", fmt.Errorf("no choices to select from")
+	}
+	return a.ask(PromptOptions{Message: message, Choices: choices, Default: def}, nil)
+}
+
+// MultiSelect asks to choose any number of the choices. The choices are
+// separated by commas or spaces and are selected like with Select.
+// An empty input selects the defaults. The choices are returned in their order.
+func (a *App) MultiSelect(message string, choices []string, defaults []string) ([]string, error) {
+	if len(choices) == 0 {
+		return nil, fmt.Errorf("no choices to select from")
+	}
+
+	var defs []string
+	for i, c := range choices {
+		if slices.Contains(defaults, c) {
+			defs = append(defs, strconv.Itoa(i+1))
+		}
+	}
+	prompt := message
+	if len(defs) > 0 {
+		prompt += " [" + strings.Join(defs, ",") + "]"
+	}
+	prompt += ": "
+
+	a.printChoices(choices, nil)
+
+Loop:
+	for {
+		value, err := a.readLine(prompt, false)
+		if err != nil {
+			return nil, err
+		}
+
+		fields := strings.FieldsFunc(value, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+		if len(fields) == 0 {
+			return filterChoices(choices, defaults), nil
+		}
+
+		po := PromptOptions{Choices: choices}
+		selected := make([]string, 0, len(fields))
+		for _, f := range fields {
+			c, matches := po.choice(f)
+			if len(matches) > 1 {
+				a.printChoices(choices, matches)
+				continue Loop
+			} else if err = po.validate(c); err != nil {
+				a.PrintError(err)
+				continue Loop
+			}
+			selected = append(selected, c)
+		}
+		return filterChoices(choices, selected), nil
+	}
+}
+
+// filterChoices returns the choices contained in values in their order.
+func filterChoices(choices, values []string) []string {
+	var filtered []string
+	for _, c := range choices {
+		if slices.Contains(values, c) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,271 @@
+package grumble
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestConfirm
+// ---------------------------------------------------------------------------
+
+func TestConfirm(t *testing.T) {
+	a := newTestApp(t, &Config{Name: "app", NoColor: true})
+	out := newScriptedShell(t, a, "maybe", "Y", "no", "", "")
+
+	for i, want := range []bool{true, false, true, false} {
+		got, err := a.Confirm("continue?", i%2 == 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%d: expected %v, got %v", i, want, got)
+		}
+	}
+	if !strings.Contains(out.String(), "error: please answer 'y' or 'n'") {
+		t.Errorf("output misses the error:\n%s", out.String())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestInput
+// ---------------------------------------------------------------------------
+
+func TestInput(t *testing.T) {
+	a := newTestApp(t, &Config{Name: "app", NoColor: true})
+	out := newScriptedShell(t, a, "", "x", "bob", "", "", "pw")
+
+	validate := func(v string) error {
+		if len(v) < 2 {
+			return errors.New("too short")
+		}
+		return nil
+	}
+
+	got, err := a.Input(PromptOptions{Message: "name", Validate: validate})
+	if err != nil || got != "bob" {
+		t.Fatalf("expected bob, got %q: %v", got, err)
+	}
+	got, err = a.Input(PromptOptions{Message: "alias", Default: "bobby"})
+	if err != nil || got != "bobby" {
+		t.Fatalf("expected the default, got %q: %v", got, err)
+	}
+	got, err = a.Input(PromptOptions{Message: "comment", AllowEmpty: true})
+	if err != nil || got != "" {
+		t.Fatalf("expected empty input, got %q: %v", got, err)
+	}
+	got, err = a.Password("password")
+	if err != nil || got != "pw" {
+		t.Fatalf("expected pw, got %q: %v", got, err)
+	}
+
+	for _, w := range []string{"error: a value is required", "error: too short"} {
+		if !strings.Contains(out.String(), w) {
+			t.Errorf("output misses %q:\n%s", w, out.String())
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestSelect
+// ---------------------------------------------------------------------------
+
+func TestSelect(t *testing.T) {
+	choices := []string{"red", "green", "blue", "black"}
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+		out   string
+	}{
+		{name: "number", lines: []string{"3"}, want: "blue"},
+		{name: "value", lines: []string{"green"}, want: "green"},
+		{name: "default", lines: []string{""}, want: "red"},
+		{name: "unique filter", lines: []string{"EE"}, want: "green"},
+		{name: "ambiguous filter", lines: []string{"bl", "ack"}, want: "black", out: "  3) blue\n  4) black\n"},
+		{name: "invalid", lines: []string{"pink", "1"}, want: "red", out: "error: invalid choice 'pink'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t, &Config{Name: "app", NoColor: true})
+			out := newScriptedShell(t, a, tt.lines...)
+
+			got, err := a.Select("color", choices, "red")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+			if !strings.Contains(out.String(), tt.out) {
+				t.Errorf("output misses %q:\n%s", tt.out, out.String())
+			}
+		})
+	}
+
+	if _, err := newTestApp(t, &Config{Name: "app", NoColor: true}).Select("color", nil, ""); err == nil {
+		t.Error("expected an error without choices")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestMultiSelect
+// ---------------------------------------------------------------------------
+
+func TestMultiSelect(t *testing.T) {
+	choices := []string{"red", "green", "blue", "black"}
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{name: "numbers", lines: []string{"4, 1"}, want: []string{"red", "black"}},
+		{name: "values and filters", lines: []string{"blue ee"}, want: []string{"green", "blue"}},
+		{name: "defaults", lines: []string{""}, want: []string{"green", "blue"}},
+		{name: "ambiguous", lines: []string{"1 bl", "1 ack"}, want: []string{"red", "black"}},
+		{name: "invalid", lines: []string{"1 pink", "2"}, want: []string{"green"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t, &Config{Name: "app", NoColor: true})
+			newScriptedShell(t, a, tt.lines...)
+
+			got, err := a.MultiSelect("colors", choices, []string{"blue", "green"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestInputNotInteractive
+// ---------------------------------------------------------------------------
+
+func TestInputNotInteractive(t *testing.T) {
+	a := newTestApp(t, &Config{Name: "app", NoColor: true})
+	if _, err := a.Confirm("continue?", true); !errors.Is(err, ErrNotInteractive) {
+		t.Errorf("expected ErrNotInteractive, got %v", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestCommandConfirm
+// ---------------------------------------------------------------------------
+
+func TestCommandConfirm(t *testing.T) {
+	newApp := func(c *Config, deleted *[]string) *App {
+		c.Name = "app"
+		c.NoColor = true
+		a := New(c)
+		a.AddCommand(&Command{
+			Name:    "delete",
+			Help:    "delete a pod",
+			Confirm: `delete pod '{{.Args.String "pod"}}'?`,
+			Args: func(a *Args) {
+				a.String("pod", "the pod")
+			},
+			Run: func(c *Context) error {
+				*deleted = append(*deleted, c.Args.String("pod"))
+				return nil
+			},
+		})
+		return a
+	}
+
+	var deleted []string
+	a := newApp(&Config{}, &deleted)
+	newScriptedShell(t, a, "y", "n")
+
+	if err := a.RunCommand([]string{"delete", "web"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RunCommand([]string{"delete", "api"}); !errors.Is(err, ErrAborted) {
+		t.Errorf("expected ErrAborted, got %v", err)
+	}
+	if err := a.RunCommand([]string{"delete", "-y", "db"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(deleted, []string{"web", "db"}) {
+		t.Errorf("unexpected deleted pods %q", deleted)
+	}
+
+	// Without interactive input, --yes is required.
+	deleted = nil
+	a = newApp(&Config{}, &deleted)
+	err := a.RunCommand([]string{"delete", "web"})
+	var ue *UsageError
+	if !errors.As(err, &ue) || !errors.Is(err, ErrNotInteractive) ||
+		!strings.HasSuffix(err.Error(), "confirm command 'delete' with '--yes'") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err = a.RunCommand([]string{"delete", "--yes", "web"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// AssumeYes confirms all commands.
+	a = newApp(&Config{AssumeYes: true}, &deleted)
+	if err = a.RunCommand([]string{"delete", "api"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(deleted, []string{"web", "api"}) {
+		t.Errorf("unexpected deleted pods %q", deleted)
+	}
+
+	assertPanics(t, "invalid confirm template", func() {
+		newApp(&Config{}, &deleted).AddCommand(&Command{Name: "x", Help: "x", Confirm: "{{.Args"})
+	})
+
+	// Commands defining their own -y keep it and get only --yes.
+	var year string
+	a = newApp(&Config{}, &deleted)
+	a.AddCommand(&Command{
+		Name:    "upgrade",
+		Help:    "upgrade the pods",
+		Confirm: "upgrade?",
+		Flags: func(f *Flags) {
+			f.String("y", "year", "", "the release year")
+		},
+		Run: func(c *Context) error {
+			year = c.Flags.String("year")
+			return nil
+		},
+	})
+	if fi := a.commands.Get("upgrade").flags.find("--yes"); fi == nil || fi.Short != "" {
+		t.Errorf("unexpected flag %+v", fi)
+	}
+	err = a.RunCommand([]string{"upgrade", "-y", "2026"})
+	if !errors.Is(err, ErrNotInteractive) || !strings.HasSuffix(err.Error(), "confirm command 'upgrade' with '--yes'") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err = a.RunCommand([]string{"upgrade", "-y", "2026", "--yes"}); err != nil || year != "2026" {
+		t.Errorf("unexpected result %q: %v", year, err)
+	}
+
+	// Commands defining their own --yes flag keep it.
+	a.AddCommand(&Command{
+		Name:    "accept",
+		Help:    "accept the terms",
+		Confirm: "accept?",
+		Flags: func(f *Flags) {
+			f.BoolL("yes", false, "answer yes")
+		},
+		Run: func(c *Context) error { return nil },
+	})
+	if fi := a.commands.Get("accept").flags.find("-y"); fi != nil {
+		t.Errorf("unexpected flag %+v", fi)
+	}
+	err = a.RunCommand([]string{"accept", "--yes"})
+	if !errors.Is(err, ErrNotInteractive) || !strings.HasSuffix(err.Error(), "command 'accept' requires confirmation") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
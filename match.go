@@ -0,0 +1,135 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MatchMode defines how completion candidates are matched against the typed prefix.
+type MatchMode int
+
+const (
+	// MatchPrefix matches candidates starting with the prefix.
+	MatchPrefix MatchMode = iota
+
+	// MatchPrefixFold matches candidates starting with the prefix, ignoring the case.
+	MatchPrefixFold
+
+	// MatchSubstring matches candidates containing the prefix, ignoring the case.
+	// Candidates are ranked by the position of the match.
+	MatchSubstring
+
+	// MatchFuzzy matches candidates containing all characters of the
+	// prefix in order, ignoring the case. Candidates are ranked by a score
+	// preferring consecutive characters and the start of words.
+	MatchFuzzy
+)
+
+// match returns true, if the candidate matches the prefix. A higher score
+// indicates a better match. Scores are only comparable for the same prefix.
+func (m MatchMode) match(candidate, prefix string) (score int, ok bool) {
+	switch m {
+	case MatchPrefixFold:
+		return 0, strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(prefix))
+	case MatchSubstring:
+		i := strings.Index(strings.ToLower(candidate), strings.ToLower(prefix))
+		return -i, i >= 0
+	case MatchFuzzy:
+		return fuzzyScore(candidate, prefix)
+	default:
+		return 0, strings.HasPrefix(candidate, prefix)
+	}
+}
+
+// filter returns all candidates matching the prefix ranked by their score.
+// Candidates with equal scores keep their order.
+func (m MatchMode) filter(prefix string, candidates []Candidate) []Candidate {
+	type scored struct {
+		cand  Candidate
+		score int
+	}
+
+	var matches []scored
+	for _, c := range candidates {
+		if score, ok := m.match(c.Value, prefix); ok {
+			matches = append(matches, scored{cand: c, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	filtered := make([]Candidate, len(matches))
+	for i, s := range matches {
+		filtered[i] = s.cand
+	}
+	return filtered
+}
+
+// fuzzyScore matches the characters of the pattern in order against the candidate.
+// Matches at the start of the candidate or a word and consecutive matches
+// score higher, while gaps between matches and unmatched characters reduce the score.
+func fuzzyScore(candidate, pattern string) (score int, ok bool) {
+	c := []rune(strings.ToLower(candidate))
+	p := []rune(strings.ToLower(pattern))
+
+	ci, prev := 0, -1
+	for _, pr := range p {
+		for ci < len(c) && c[ci] != pr {
+			ci++
+		}
+		if ci == len(c) {
+			return 0, false
+		}
+
+		switch {
+		case ci == 0:
+			score += 8
+		case ci == prev+1:
+			score += 5
+		case isWordSeparator(c[ci-1]):
+			score += 6
+		default:
+			score++
+		}
+		if prev >= 0 {
+			score -= min(ci-prev-1, 3)
+		}
+
+		prev = ci
+		ci++
+	}
+
+	// Prefer shorter candidates.
+	score -= (len(c) - len(p)) / 4
+	return score, true
+}
+
+func isWordSeparator(r rune) bool {
+	return r == '-' || r == '_' || r == '.' || r == '/' || unicode.IsSpace(r)
+}
@@ -0,0 +1,84 @@
+package grumble
+
+import (
+	"reflect"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestMatchModes
+// ---------------------------------------------------------------------------
+
+func TestMatchModes(t *testing.T) {
+	tests := []struct {
+		mode      MatchMode
+		candidate string
+		prefix    string
+		want      bool
+	}{
+		{MatchPrefix, "deploy", "dep", true},
+		{MatchPrefix, "deploy", "Dep", false},
+		{MatchPrefix, "deploy", "ploy", false},
+		{MatchPrefixFold, "deploy", "DeP", true},
+		{MatchPrefixFold, "deploy", "ploy", false},
+		{MatchSubstring, "deploy", "PLO", true},
+		{MatchSubstring, "deploy", "dpl", false},
+		{MatchFuzzy, "deploy", "dpl", true},
+		{MatchFuzzy, "show-interfaces", "shint", true},
+		{MatchFuzzy, "deploy", "dlp", false},
+		{MatchFuzzy, "deploy", "", true},
+	}
+
+	for _, tt := range tests {
+		if _, ok := tt.mode.match(tt.candidate, tt.prefix); ok != tt.want {
+			t.Errorf("mode %d: match(%q, %q) = %v, want %v", tt.mode, tt.candidate, tt.prefix, ok, tt.want)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestMatchFilterRanking
+// ---------------------------------------------------------------------------
+
+func TestMatchFilterRanking(t *testing.T) {
+	values := func(cands []Candidate) (v []string) {
+		for _, c := range cands {
+			v = append(v, c.Value)
+		}
+		return
+	}
+	cands := []Candidate{
+		{Value: "list-deployments"},
+		{Value: "undeploy"},
+		{Value: "deploy"},
+		{Value: "delete-pods"},
+	}
+
+	t.Run("substring", func(t *testing.T) {
+		got := values(MatchSubstring.filter("deploy", cands))
+		want := []string{"deploy", "undeploy", "list-deployments"}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("fuzzy", func(t *testing.T) {
+		got := values(MatchFuzzy.filter("dep", cands))
+		if len(got) != 4 || got[0] != "deploy" {
+			t.Fatalf("expected 'deploy' to rank first of all, got %v", got)
+		}
+
+		got = values(MatchFuzzy.filter("dp", cands))
+		if len(got) == 0 || got[0] != "delete-pods" {
+			t.Fatalf("expected 'delete-pods' to rank first, got %v", got)
+		}
+	})
+
+	t.Run("prefix keeps order", func(t *testing.T) {
+		got := values(MatchPrefix.filter("de", cands))
+		want := []string{"deploy", "delete-pods"}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	shlex "github.com/desertbit/go-shlex"
+	"golang.org/x/term"
+)
+
+// Pager buffers output and pages it on Close, if it exceeds the terminal height.
+// The output is written unchanged, if it is not written to a terminal.
+type Pager struct {
+	a   *App
+	out io.Writer // Captured output, which is not paged.
+	buf bytes.Buffer
+}
+
+// Pager returns a new pager writing to the app output.
+func (a *App) Pager() *Pager {
+	return &Pager{a: a}
+}
+
+// Write to the pager buffer.
+func (p *Pager) Write(b []byte) (int, error) {
+	return p.buf.Write(b)
+}
+
+// Printf formats according to a format specifier and writes to the pager.
+func (p *Pager) Printf(format string, args ...interface{}) (int, error) {
+	return fmt.Fprintf(&p.buf, format, args...)
+}
+
+// Println formats using the default formats for its operands and writes to the pager.
+func (p *Pager) Println(args ...interface{}) (int, error) {
+	return fmt.Fprintln(&p.buf, args...)
+}
+
+// Print formats using the default formats for its operands and writes to the pager.
+func (p *Pager) Print(args ...interface{}) (int, error) {
+	return fmt.Fprint(&p.buf, args...)
+}
+
+// Close writes the buffered output. It is paged with the configured pager
+// command, the PAGER environment variable or the builtin pager, if it
+// does not fit on the terminal.
+func (p *Pager) Close() error {
+	a := p.a
+	out := p.buf.String()
+	p.buf.Reset()
+
+	if p.out != nil {
+		_, err := p.out.Write([]byte(out))
+		return err
+	}
+
+	f, width, height := a.pagerTerminal()
+	if f == nil || lineRows(out, width) < height {
+		_, err := a.Stdout().Write([]byte(out))
+		return err
+	}
+
+	name := a.config.Pager
+	if len(name) == 0 {
+		name = os.Getenv("PAGER")
+	}
+	if len(name) > 0 {
+		return runPager(name, out, f)
+	}
+	return a.page(out, width, height)
+}
+
+// pagerTerminal returns the terminal file of the app output with its size.
+// The file is nil, if the output should not be paged.
+// Readline does not read from the terminal while a command is running.
+func (a *App) pagerTerminal() (f *os.File, width, height int) {
+	if a.config.NoPager || !a.interactive() {
+		return nil, 0, 0
+	}
+
+	// Only local terminals can be paged, e.g. not remote shells.
+	f, ok := a.in.Config.Stdout.(*os.File)
+	if !ok {
+		return nil, 0, 0
+	}
+	width, height, err := term.GetSize(int(f.Fd()))
+	if err != nil || height < 2 {
+		return nil, 0, 0
+	}
+	return f, width, height
+}
+
+// runPager pipes the output through the pager command.
+func runPager(name, out string, f *os.File) error {
+	args, err := shlex.Split(name, true)
+	if err != nil || len(args) == 0 {
+		return fmt.Errorf("invalid pager '%s'", name)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(out)
+	cmd.Stdout = f
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("pager '%s': %w", name, err)
+	}
+	return nil
+}
+
+// page writes the output page by page. Each page fills the height of the
+// terminal except the last line, which asks to continue.
+func (a *App) page(out string, width, height int) error {
+	lines := strings.SplitAfter(out, "\n")
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	rows := 0
+	for _, l := range lines {
+		r := lineRows(l, width)
+		if rows > 0 && rows+r > height-1 {
+			answer, err := a.readLine("-- More -- (enter: continue, q: quit) ", false)
+			if err != nil {
+				return err
+			} else if strings.EqualFold(strings.TrimSpace(answer), "q") {
+				return nil
+			}
+			rows = 0
+		}
+
+		_, err := a.Stdout().Write([]byte(l))
+		if err != nil {
+			return err
+		}
+		rows += r
+	}
+	return nil
+}
+
+// lineRows returns the number of terminal rows the text occupies.
+// Lines longer than the width are wrapped by the terminal.
+func lineRows(s string, width int) int {
+	rows := 0
+	for _, l := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		w := textWidth(l)
+		if width <= 0 || w <= width {
+			rows++
+		} else {
+			rows += (w + width - 1) / width
+		}
+	}
+	return rows
+}
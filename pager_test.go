@@ -0,0 +1,101 @@
+package grumble
+
+import (
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestPagerPassThrough
+// ---------------------------------------------------------------------------
+
+func TestPagerPassThrough(t *testing.T) {
+	a := newTestApp(t, nil)
+	out := newScriptedShell(t, a)
+
+	// The scripted output is no terminal, which could be paged.
+	p := a.Pager()
+	for i := 0; i < 100; i++ {
+		p.Printf("line %d\n", i)
+	}
+	if out.Len() != 0 {
+		t.Fatal("expected the output to be buffered until close")
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "line 0\nline 1\n") || !strings.HasSuffix(out.String(), "line 99\n") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "More") {
+		t.Errorf("expected no pager prompt:\n%s", out.String())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestPagerPage
+// ---------------------------------------------------------------------------
+
+func TestPagerPage(t *testing.T) {
+	text := "1\n2\n3\n4\n5\n6\n7\n"
+
+	t.Run("all pages", func(t *testing.T) {
+		a := newTestApp(t, nil)
+		out := newScriptedShell(t, a, "", "")
+
+		if err := a.page(text, 80, 4); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != text {
+			t.Errorf("unexpected output %q", out.String())
+		}
+	})
+
+	t.Run("quit", func(t *testing.T) {
+		a := newTestApp(t, nil)
+		out := newScriptedShell(t, a, "q")
+
+		if err := a.page(text, 80, 4); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != "1\n2\n3\n" {
+			t.Errorf("unexpected output %q", out.String())
+		}
+	})
+
+	t.Run("wrapped lines", func(t *testing.T) {
+		a := newTestApp(t, nil)
+		out := newScriptedShell(t, a, "q")
+
+		if err := a.page("1234567890\nab\ncd\n", 5, 4); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != "1234567890\nab\n" {
+			t.Errorf("unexpected output %q", out.String())
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// TestLineRows
+// ---------------------------------------------------------------------------
+
+func TestLineRows(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  int
+	}{
+		{s: "", width: 10, want: 1},
+		{s: "a\nb\n", width: 10, want: 2},
+		{s: "0123456789", width: 10, want: 1},
+		{s: "0123456789a\nb", width: 10, want: 3},
+		{s: "日本語", width: 4, want: 2},
+		{s: "0123456789", width: 0, want: 1},
+	}
+	for _, tt := range tests {
+		if got := lineRows(tt.s, tt.width); got != tt.want {
+			t.Errorf("lineRows(%q, %d) = %d, want %d", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
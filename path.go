@@ -0,0 +1,181 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type pathKind int
+
+const (
+	pathAny pathKind = iota
+	pathFile
+	pathDir
+)
+
+func (k pathKind) String() string {
+	switch k {
+	case pathFile:
+		return "file"
+	case pathDir:
+		return "dir"
+	default:
+		return "any"
+	}
+}
+
+// PathOptions configures the completion and validation of path arguments and flags.
+type PathOptions struct {
+	// Extensions filters the completed files by their extension, e.g. ".json".
+	// Directories are always completed.
+	Extensions []string
+
+	// Hidden completes hidden files and directories without requiring
+	// the prefix to start with a dot.
+	Hidden bool
+
+	// MustExist validates during parsing, that the path exists.
+	MustExist bool
+}
+
+// PathCompleter returns a completion function for file system paths.
+func PathCompleter(po PathOptions) CompleteFunc {
+	return newPathCompleter(po, pathAny)
+}
+
+func newPathCompleter(po PathOptions, kind pathKind) CompleteFunc {
+	return func(prefix string, _ FlagMap, _ ArgMap) []string {
+		return po.complete(prefix, kind)
+	}
+}
+
+// complete returns all paths matching the prefix.
+// Directories are suffixed with a slash.
+func (po PathOptions) complete(prefix string, kind pathKind) (candidates []string) {
+	if prefix == "~" {
+		return []string{"~/"}
+	}
+
+	// Split the prefix into the directory to list and the base name to match.
+	dir, base := "", prefix
+	if i := strings.LastIndexAny(prefix, "/"+string(filepath.Separator)); i >= 0 {
+		dir, base = prefix[:i+1], prefix[i+1:]
+	}
+
+	listDir := expandHome(dir)
+	if len(listDir) == 0 {
+		listDir = "."
+	}
+
+	entries, err := os.ReadDir(listDir)
+	if err != nil {
+		return nil
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, base) {
+			continue
+		}
+
+		// Hidden files are only completed if requested or typed explicitly.
+		if strings.HasPrefix(name, ".") && !po.Hidden && !strings.HasPrefix(base, ".") {
+			continue
+		}
+
+		// Follow symlinks to decide, if it is a directory.
+		isDir := e.IsDir()
+		if e.Type()&os.ModeSymlink != 0 {
+			if fi, err := os.Stat(filepath.Join(listDir, name)); err == nil {
+				isDir = fi.IsDir()
+			}
+		}
+
+		if isDir {
+			candidates = append(candidates, dir+name+"/")
+		} else if kind != pathDir && po.matchExtension(name) {
+			candidates = append(candidates, dir+name)
+		}
+	}
+
+	return candidates
+}
+
+// matchExtension returns true, if no extensions are set or the
+// name has one of the extensions.
+func (po PathOptions) matchExtension(name string) bool {
+	if len(po.Extensions) == 0 {
+		return true
+	}
+	for _, ext := range po.Extensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// parse expands the home directory of the path and validates it,
+// if the path must exist.
+func (po PathOptions) parse(value string, kind pathKind) (string, error) {
+	p := expandHome(value)
+	if !po.MustExist {
+		return p, nil
+	}
+
+	fi, err := os.Stat(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("path does not exist")
+		}
+		return "", err
+	}
+
+	if kind == pathFile && fi.IsDir() {
+		return "", fmt.Errorf("path is a directory")
+	} else if kind == pathDir && !fi.IsDir() {
+		return "", fmt.Errorf("path is not a directory")
+	}
+
+	return p, nil
+}
+
+// expandHome replaces a leading tilde with the user's home directory.
+// The path is returned unchanged, if the home directory is unknown.
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return home + p[1:]
+}
@@ -0,0 +1,184 @@
+package grumble
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// newTestDir creates a temporary directory with some files and directories.
+func newTestDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"a.yaml", "b.txt", "my file.yaml", ".hidden"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// ---------------------------------------------------------------------------
+// TestPathComplete
+// ---------------------------------------------------------------------------
+
+func TestPathComplete(t *testing.T) {
+	dir := newTestDir(t) + "/"
+
+	tests := []struct {
+		name   string
+		po     PathOptions
+		kind   pathKind
+		prefix string
+		want   []string
+	}{
+		{name: "all", prefix: dir, want: []string{"a.yaml", "b.txt", "my file.yaml", "sub/"}},
+		{name: "prefix", prefix: dir + "m", want: []string{"my file.yaml"}},
+		{name: "hidden by prefix", prefix: dir + ".", want: []string{".hidden"}},
+		{name: "hidden option", po: PathOptions{Hidden: true}, prefix: dir, want: []string{".hidden", "a.yaml", "b.txt", "my file.yaml", "sub/"}},
+		{name: "extensions", po: PathOptions{Extensions: []string{".yaml"}}, prefix: dir, want: []string{"a.yaml", "my file.yaml", "sub/"}},
+		{name: "dirs only", kind: pathDir, prefix: dir, want: []string{"sub/"}},
+		{name: "missing dir", prefix: dir + "nope/", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.po.complete(tt.prefix, tt.kind)
+			sort.Strings(got)
+
+			var want []string
+			for _, w := range tt.want {
+				want = append(want, dir+w)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("expected %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestPathCompleteHome
+// ---------------------------------------------------------------------------
+
+func TestPathCompleteHome(t *testing.T) {
+	t.Setenv("HOME", newTestDir(t))
+
+	if got := (PathOptions{}).complete("~", pathAny); !reflect.DeepEqual(got, []string{"~/"}) {
+		t.Fatalf("expected [~/], got %q", got)
+	}
+	if got := (PathOptions{}).complete("~/s", pathAny); !reflect.DeepEqual(got, []string{"~/sub/"}) {
+		t.Fatalf("expected [~/sub/], got %q", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestPathArgs
+// ---------------------------------------------------------------------------
+
+func TestPathArgs(t *testing.T) {
+	dir := newTestDir(t)
+	t.Setenv("HOME", dir)
+
+	t.Run("file expands home", func(t *testing.T) {
+		var a Args
+		a.File("config", "a config file", PathOptions{MustExist: true})
+
+		res := make(ArgMap)
+		_, err := a.parse([]string{"~/a.yaml"}, res)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v := res.String("config"); v != filepath.Join(dir, "a.yaml") {
+			t.Fatalf("expected expanded path, got '%s'", v)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		reg     func(a *Args)
+		value   string
+		wantErr bool
+	}{
+		{name: "file missing", reg: func(a *Args) { a.File("p", "help", PathOptions{MustExist: true}) }, value: "nope", wantErr: true},
+		{name: "file is dir", reg: func(a *Args) { a.File("p", "help", PathOptions{MustExist: true}) }, value: "sub", wantErr: true},
+		{name: "file not validated", reg: func(a *Args) { a.File("p", "help", PathOptions{}) }, value: "nope"},
+		{name: "dir", reg: func(a *Args) { a.Dir("p", "help", PathOptions{MustExist: true}) }, value: "sub"},
+		{name: "dir is file", reg: func(a *Args) { a.Dir("p", "help", PathOptions{MustExist: true}) }, value: "b.txt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a Args
+			tt.reg(&a)
+
+			_, err := a.parse([]string{filepath.Join(dir, tt.value)}, make(ArgMap))
+			if tt.wantErr && err == nil {
+				t.Fatal("expected error, got nil")
+			} else if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestPathFlag
+// ---------------------------------------------------------------------------
+
+func TestPathFlag(t *testing.T) {
+	dir := newTestDir(t)
+
+	f := &Flags{}
+	f.Path("o", "output", "", "output path", PathOptions{MustExist: true})
+
+	_, res := mustParse(t, f, []string{"-o", filepath.Join(dir, "sub")})
+	if v := res.String("output"); v != filepath.Join(dir, "sub") {
+		t.Fatalf("expected sub path, got '%s'", v)
+	}
+	mustFailParse(t, f, []string{"-o", filepath.Join(dir, "nope")})
+}
+
+// ---------------------------------------------------------------------------
+// TestPathCompleterQuoting
+// ---------------------------------------------------------------------------
+
+func TestPathCompleterQuoting(t *testing.T) {
+	dir := newTestDir(t)
+
+	var c Commands
+	cmd := &Command{
+		Name: "cat",
+		Help: "print a file",
+		Args: func(a *Args) {
+			a.File("file", "the file", PathOptions{})
+		},
+	}
+	cmd.registerFlagsAndArgs(true)
+	c.Add(cmd)
+	cp := newCompleter(&c)
+
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{name: "escape spaces", line: "cat " + dir + "/my", want: []string{"\\ file.yaml "}},
+		{name: "escaped prefix", line: "cat " + dir + "/my\\ f", want: []string{"ile.yaml "}},
+		{name: "open quote", line: "cat \"" + dir + "/my f", want: []string{"ile.yaml\" "}},
+		{name: "directory without space", line: "cat " + dir + "/s", want: []string{"ub/"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := complete(cp, tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/desertbit/readline"
+)
+
+const (
+	// progressInterval is the refresh interval of spinners.
+	progressInterval = 100 * time.Millisecond
+
+	// progressLogInterval is the min interval of progress log lines,
+	// which are written instead of updating the line in place.
+	progressLogInterval = 5 * time.Second
+
+	// progressMaxBarWidth is the max width of the progress bar.
+	progressMaxBarWidth = 40
+)
+
+var spinnerFrames = []string{"-", "\\", "|", "/"}
+
+// Progress is a progress bar or a spinner. It is updated in place on a
+// terminal and written as periodic log lines otherwise or if colors are disabled.
+// It is stopped automatically when the command returns.
+type Progress struct {
+	c     *Context
+	total int64 // Zero for spinners.
+	tty   bool
+
+	mu      sync.Mutex
+	message string
+	current int64
+	frame   int
+	logged  time.Time
+	done    bool
+	stop    chan struct{}
+}
+
+// Progress starts a progress bar with the message and the total count.
+func (c *Context) Progress(message string, total int64) *Progress {
+	if total <= 0 {
+		total = 1
+	}
+	return c.startProgress(message, total)
+}
+
+// Spinner starts a spinner with the message for a task of unknown length.
+func (c *Context) Spinner(message string) *Progress {
+	return c.startProgress(message, 0)
+}
+
+func (c *Context) startProgress(message string, total int64) *Progress {
+	p := &Progress{
+		c:       c,
+		total:   total,
+		tty:     c.stdout == nil && c.App.outputTerminal(),
+		message: message,
+		stop:    make(chan struct{}),
+	}
+
+	// Progress can be started by other goroutines, which might still
+	// run after the command returned. It is stopped right away then.
+	c.progressMutex.Lock()
+	defer c.progressMutex.Unlock()
+	if c.progressStopped {
+		p.done = true
+		return p
+	}
+	c.progress = append(c.progress, p)
+
+	p.mu.Lock()
+	p.render(true)
+	p.mu.Unlock()
+
+	if total == 0 && p.tty {
+		go p.spin()
+	}
+	return p
+}
+
+// stopProgress stops all running progress bars and spinners of the context.
+func (c *Context) stopProgress() {
+	c.progressMutex.Lock()
+	progress := c.progress
+	c.progress = nil
+	c.progressStopped = true
+	c.progressMutex.Unlock()
+
+	for _, p := range progress {
+		p.finish(false)
+	}
+}
+
+// Add increments the current count.
+func (p *Progress) Add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.set(p.current + n)
+}
+
+// Set sets the current count.
+func (p *Progress) Set(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.set(n)
+}
+
+func (p *Progress) set(n int64) {
+	if p.done {
+		return
+	}
+	p.current = min(max(n, 0), p.total)
+	p.render(false)
+}
+
+// SetMessage changes the message.
+func (p *Progress) SetMessage(message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done {
+		return
+	}
+	p.message = message
+	p.render(false)
+}
+
+// Println writes a line above the progress without breaking it.
+func (p *Progress) Println(args ...interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tty && !p.done {
+		p.write("\r\033[K" + fmt.Sprintln(args...))
+		p.render(true)
+		return
+	}
+	p.write(fmt.Sprintln(args...))
+}
+
+// Done completes the progress and leaves its final state on its own line.
+func (p *Progress) Done() {
+	p.finish(true)
+}
+
+// finish stops the progress. The final state is written, if completed.
+// Otherwise the line is cleared on a terminal.
+func (p *Progress) finish(completed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done {
+		return
+	}
+	p.done = true
+	close(p.stop)
+
+	switch {
+	case completed:
+		if p.total > 0 {
+			p.current = p.total
+		}
+		p.render(true)
+		if p.tty {
+			p.write("\n")
+		}
+	case p.tty:
+		p.write("\r\033[K")
+	}
+}
+
+// spin refreshes the spinner until the progress is done.
+func (p *Progress) spin() {
+	t := time.NewTicker(progressInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-t.C:
+			p.mu.Lock()
+			if !p.done {
+				p.frame++
+				p.render(false)
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+// render writes the progress. Without a terminal, a log line is only
+// written if forced or the log interval passed. The lock must be held.
+func (p *Progress) render(force bool) {
+	if p.tty {
+		p.write("\r\033[K" + p.line(p.c.App.outputWidth()))
+		return
+	}
+
+	now := time.Now()
+	if !force && now.Sub(p.logged) < progressLogInterval {
+		return
+	}
+	p.logged = now
+	p.write(p.logLine() + "\n")
+}
+
+// line returns the progress line fitting the width.
+func (p *Progress) line(width int) string {
+	if p.total == 0 {
+		if p.done {
+			return truncate(p.logLine(), width)
+		}
+		return truncate(spinnerFrames[p.frame%len(spinnerFrames)]+" "+p.message, width)
+	}
+
+	suffix := fmt.Sprintf(" %3d%% %d/%d", p.percent(), p.current, p.total)
+	barWidth := min(width-textWidth(p.message)-textWidth(suffix)-3, progressMaxBarWidth)
+	if width <= 0 {
+		barWidth = progressMaxBarWidth
+	}
+	if barWidth < 10 {
+		return truncate(p.message+suffix, width)
+	}
+
+	filled := int(int64(barWidth) * p.current / p.total)
+	bar := strings.Repeat("=", filled)
+	if filled < barWidth {
+		bar += ">" + strings.Repeat(" ", barWidth-filled-1)
+	}
+	return p.message + " [" + bar + "]" + suffix
+}
+
+// logLine returns the progress as plain log line.
+func (p *Progress) logLine() string {
+	switch {
+	case p.total > 0:
+		return fmt.Sprintf("%s: %d%% (%d/%d)", p.message, p.percent(), p.current, p.total)
+	case p.done:
+		return p.message + ": done"
+	default:
+		return p.message + "..."
+	}
+}
+
+func (p *Progress) percent() int {
+	return int(p.current * 100 / p.total)
+}
+
+func (p *Progress) write(s string) {
+	_, _ = p.c.Stdout().Write([]byte(s))
+}
+
+// outputTerminal returns true, if the app output is a terminal,
+// which can be updated in place.
+func (a *App) outputTerminal() bool {
+	return !a.config.NoColor && a.in != nil && a.in.Config.FuncIsTerminal()
+}
+
+// outputWidth returns the width of the terminal.
+func (a *App) outputWidth() int {
+	if a.in != nil {
+		return a.in.Config.FuncGetWidth()
+	}
+	return readline.GetScreenWidth()
+}
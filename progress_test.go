@@ -0,0 +1,149 @@
+package grumble
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// TestProgressLine
+// ---------------------------------------------------------------------------
+
+func TestProgressLine(t *testing.T) {
+	p := &Progress{message: "copy", total: 4, current: 1}
+
+	tests := []struct {
+		width int
+		want  string
+	}{
+		{width: 40, want: "copy [======>" + strings.Repeat(" ", 17) + "]  25% 1/4"},
+		{width: 0, want: "copy [==========>" + strings.Repeat(" ", 29) + "]  25% 1/4"},
+		{width: 20, want: "copy  25% 1/4"},
+		{width: 10, want: "copy  25%…"},
+	}
+	for _, tt := range tests {
+		if got := p.line(tt.width); got != tt.want {
+			t.Errorf("width %d: expected %q, got %q", tt.width, tt.want, got)
+		}
+	}
+
+	p.current = 4
+	if got := p.line(30); got != "copy [==============] 100% 4/4" {
+		t.Errorf("unexpected completed line %q", got)
+	}
+
+	s := &Progress{message: "waiting", frame: 5}
+	if got := s.line(80); got != "\\ waiting" {
+		t.Errorf("unexpected spinner line %q", got)
+	}
+	s.done = true
+	if got := s.line(80); got != "waiting: done" {
+		t.Errorf("unexpected done spinner line %q", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestProgressTerminal
+// ---------------------------------------------------------------------------
+
+func TestProgressTerminal(t *testing.T) {
+	var b strings.Builder
+	c := newContext(newTestApp(t, nil), nil, nil, nil)
+	c.stdout = &b
+
+	p := &Progress{c: c, message: "copy", total: 2, tty: true, stop: make(chan struct{})}
+	p.Add(1)
+	p.Println("copied a")
+	p.Done()
+	p.Add(1)
+
+	want := "\r\033[K" + p.line(0) + "\n"
+	if !strings.HasSuffix(b.String(), want) {
+		t.Errorf("expected output to end with %q, got %q", want, b.String())
+	}
+	for _, w := range []string{"\r\033[Kcopy [", " 50% 1/2", "\r\033[Kcopied a\n"} {
+		if !strings.Contains(b.String(), w) {
+			t.Errorf("output misses %q: %q", w, b.String())
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestProgressLog
+// ---------------------------------------------------------------------------
+
+func TestProgressLog(t *testing.T) {
+	a := newTestApp(t, nil, &Command{
+		Name: "copy",
+		Help: "copy files",
+		Run: func(c *Context) error {
+			p := c.Progress("copy", 10)
+			for i := 0; i < 10; i++ {
+				p.Add(1)
+			}
+			p.Done()
+
+			c.Spinner("wait")
+			return nil
+		},
+	})
+
+	b := newScriptedShell(t, a)
+	if err := a.RunCommand([]string{"copy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The updates within the log interval are skipped and the
+	// unfinished spinner is stopped without a final line.
+	want := "copy: 0% (0/10)\ncopy: 100% (10/10)\nwait...\n"
+	if b.String() != want {
+		t.Errorf("expected %q, got %q", want, b.String())
+	}
+}
+
+func TestProgressGoroutines(t *testing.T) {
+	var (
+		wg   sync.WaitGroup
+		late *Progress
+	)
+	a := newTestApp(t, nil, &Command{
+		Name: "copy",
+		Help: "copy files",
+		Run: func(c *Context) error {
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					c.Progress(fmt.Sprintf("copy %d", i), 10).Add(1)
+				}()
+			}
+			wg.Wait()
+
+			// Progress started after the command returned is stopped.
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				time.Sleep(10 * time.Millisecond)
+				late = c.Spinner("late")
+			}()
+			return nil
+		},
+	})
+
+	b := newScriptedShell(t, a)
+	if err := a.RunCommand([]string{"copy"}); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	if strings.Count(b.String(), "% (0/10)\n") != 4 || strings.Contains(b.String(), "late") {
+		t.Errorf("unexpected output %q", b.String())
+	}
+	late.SetMessage("still late")
+	if strings.Contains(b.String(), "late") {
+		t.Errorf("unexpected output %q", b.String())
+	}
+}
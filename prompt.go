@@ -0,0 +1,272 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	shlex "github.com/desertbit/go-shlex"
+)
+
+// PromptOptions configures how a missing argument or flag value is asked for.
+type PromptOptions struct {
+	// Message is shown as prompt. Defaults to the help message.
+	Message string
+
+	// Default is used, if the input is empty.
+	Default string
+
+	// Choices restricts the input to one of the values.
+	// A choice can be selected by its value or its listed number.
+	Choices []string
+
+	// Secret masks the input.
+	Secret bool
+
+	// AllowEmpty accepts an empty input, if no default is set.
+	// Missing args and flags always require a value.
+	AllowEmpty bool
+
+	// Validate checks the input in addition to the argument or flag parser.
+	Validate func(value string) error
+}
+
+// canPrompt returns true, if missing args and flags should be asked for.
+func (a *App) canPrompt() bool {
+	return a.config.PromptMissing && a.isShell && a.rl != nil
+}
+
+// readLine reads a single line of input with the prompt.
+// History and completion are disabled and the input is masked, if requested.
+func (a *App) readLine(prompt string, mask bool) (string, error) {
+	if a.in == nil {
+		return "", ErrNotInteractive
+	}
+
+	cfg := a.in.GenPasswordConfig()
+	cfg.Prompt = prompt
+	cfg.EnableMask = mask
+	cfg.Stdin = a.in.Config.Stdin
+	cfg.FuncIsTerminal = a.in.Config.FuncIsTerminal
+	cfg.FuncGetWidth = a.in.Config.FuncGetWidth
+
+	line, err := a.in.ReadPasswordWithConfig(cfg)
+	return string(line), err
+}
+
+// ask prompts for a value until it is valid. The value is additionally
+// validated by the check function, which is usually the value parser.
+func (a *App) ask(po PromptOptions, check func(value string) error) (string, error) {
+	prompt := po.Message
+	if len(po.Default) > 0 {
+		prompt += " [" + po.Default + "]"
+	}
+	prompt += ": "
+
+	a.printChoices(po.Choices, nil)
+
+	for {
+		value, err := a.readLine(prompt, po.Secret)
+		if err != nil {
+			return "", err
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) == 0 {
+			value = po.Default
+		}
+		if len(value) == 0 && po.AllowEmpty {
+			return value, nil
+		}
+
+		value, matches := po.choice(value)
+		if len(matches) > 1 {
+			a.printChoices(po.Choices, matches)
+			continue
+		}
+
+		err = po.validate(value)
+		if err == nil && check != nil {
+			err = check(value)
+		}
+		if err == nil {
+			return value, nil
+		}
+		a.PrintError(err)
+	}
+}
+
+// printChoices lists the choices with their numbers.
+// Only the choices with the given indexes are listed, if set.
+func (a *App) printChoices(choices []string, indexes []int) {
+	for i, c := range choices {
+		if indexes == nil || slices.Contains(indexes, i) {
+			a.Printf("  %d) %s\n", i+1, c)
+		}
+	}
+}
+
+// choice returns the choice selected by its listed number, its value or
+// a filter matching only this choice. Otherwise the value itself is returned
+// with the indexes of the choices matching it as filter.
+func (po *PromptOptions) choice(value string) (string, []int) {
+	if n, err := strconv.Atoi(value); err == nil && n > 0 && n <= len(po.Choices) {
+		return po.Choices[n-1], nil
+	} else if len(value) == 0 || slices.Contains(po.Choices, value) {
+		return value, nil
+	}
+
+	var matches []int
+	for i, c := range po.Choices {
+		if _, ok := MatchSubstring.match(c, value); ok {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 1 {
+		return po.Choices[matches[0]], nil
+	}
+	return value, matches
+}
+
+func (po *PromptOptions) validate(value string) error {
+	if len(value) == 0 {
+		return fmt.Errorf("a value is required")
+	}
+
+	if len(po.Choices) > 0 && !slices.Contains(po.Choices, value) {
+		return fmt.Errorf("invalid choice '%s'", value)
+	}
+
+	if po.Validate != nil {
+		return po.Validate(value)
+	}
+	return nil
+}
+
+// promptFlag asks for the value of the flag and sets it.
+func (a *App) promptFlag(fi *flagItem, res FlagMap) error {
+	po := PromptOptions{Message: fi.Help}
+	if fi.prompt != nil {
+		po = *fi.prompt
+		if len(po.Message) == 0 {
+			po.Message = fi.Help
+		}
+	}
+
+	var v interface{}
+	_, err := a.ask(po, func(value string) (err error) {
+		v, err = fi.parser(value)
+		return
+	})
+	if err != nil {
+		return err
+	}
+
+	res[fi.Long] = &FlagMapItem{Value: v}
+	return nil
+}
+
+// promptArg asks for the value of the argument. List values are split
+// like the command line.
+func (a *App) promptArg(item *argItem) ([]string, error) {
+	po := PromptOptions{Message: item.Help}
+	if item.prompt != nil {
+		po = *item.prompt
+		if len(po.Message) == 0 {
+			po.Message = item.Help
+		}
+	}
+
+	var values []string
+	_, err := a.ask(po, func(value string) (err error) {
+		values = []string{value}
+		if item.isList {
+			values, err = shlex.Split(value, true)
+			if err != nil {
+				return
+			}
+		}
+		_, err = item.parser(values, make(ArgMap))
+		return
+	})
+	return values, err
+}
+
+// checkRequiredFlags returns an error, if a required flag of the app or of
+// the command path is not set. The app flags are not required by builtin
+// commands. The missing flags are asked for, if prompt is true.
+func (a *App) checkRequiredFlags(cmds []*Command, fg FlagMap, prompt bool) error {
+	var all []*Flags
+	if len(cmds) > 0 && !cmds[len(cmds)-1].isBuiltin {
+		all = append(all, &a.flags)
+	}
+	for _, c := range cmds {
+		all = append(all, &c.flags)
+	}
+
+	for _, flags := range all {
+		for _, fi := range flags.list {
+			if !fi.required {
+				continue
+			} else if i, ok := fg[fi.Long]; ok && !i.IsDefault {
+				continue
+			}
+
+			if !prompt {
+				return &FlagError{Flag: fi.Long, Err: fmt.Errorf("%w '--%s'", ErrMissingFlag, fi.Long)}
+			}
+			err := a.promptFlag(fi, fg)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseArgs parses the args of the command. Missing args are asked for, if prompt is true.
+// The prompted values are appended to the args, which are returned with the rest.
+func (a *App) parseArgs(cmd *Command, args []string, res ArgMap, prompt bool) ([]string, error) {
+	for {
+		rest, err := cmd.args.parse(args, res)
+		if err == nil || !prompt || !errors.Is(err, ErrMissingArgument) {
+			return rest, err
+		}
+
+		var ae *ArgError
+		if !errors.As(err, &ae) {
+			return nil, err
+		}
+		values, err := a.promptArg(cmd.args.find(ae.Arg))
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, values...)
+	}
+}
@@ -0,0 +1,297 @@
+package grumble
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/desertbit/readline"
+)
+
+// scriptInput is the input of a scripted shell. It returns a single line
+// per read. Readline only reads, while a Readline call waits for input,
+// so each line is read by its own call.
+type scriptInput struct {
+	mu     sync.Mutex
+	lines  []string
+	closed bool
+
+	once sync.Once
+	read chan struct{} // Closed by the first read.
+}
+
+func newScriptInput(lines []string) *scriptInput {
+	return &scriptInput{lines: lines, read: make(chan struct{})}
+}
+
+func (s *scriptInput) Read(p []byte) (int, error) {
+	s.once.Do(func() { close(s.read) })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || len(s.lines) == 0 {
+		return 0, io.EOF
+	}
+
+	line := s.lines[0] + "\n"
+	n := copy(p, line)
+	if n < len(line) {
+		s.lines[0] = line[n : len(line)-1]
+	} else {
+		s.lines = s.lines[1:]
+	}
+	return n, nil
+}
+
+func (s *scriptInput) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	return nil
+}
+
+// lockedBuffer is a strings.Builder safe for concurrent writes.
+type lockedBuffer struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (l *lockedBuffer) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.Write(p)
+}
+
+func (l *lockedBuffer) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.String()
+}
+
+func (l *lockedBuffer) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b.Len()
+}
+
+// newScriptedShell makes the app an interactive shell, which reads its input
+// from the given lines. The output is written to the returned buffer.
+// The readline config is set up like by Run.
+func newScriptedShell(t *testing.T, a *App, lines ...string) *lockedBuffer {
+	t.Helper()
+
+	var out lockedBuffer
+	in := newScriptInput(lines)
+	config := &readline.Config{
+		Stdin:          in,
+		Stdout:         &out,
+		Stderr:         &out,
+		FuncIsTerminal: func() bool { return false },
+		FuncGetWidth:   func() int { return 80 },
+	}
+	a.setReadlineDefaults(config)
+	rl, err := readline.NewEx(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The terminal goroutine of readline registers itself for Close. Wait
+	// until it runs by letting it read the end of the input.
+	t.Cleanup(func() {
+		in.Close()
+		rl.Terminal.KickRead()
+		<-in.read
+		rl.Close()
+	})
+
+	a.rl, a.in = rl, rl
+	a.isShell = true
+	return &out
+}
+
+// ---------------------------------------------------------------------------
+// TestPromptMissing
+// ---------------------------------------------------------------------------
+
+func TestPromptMissing(t *testing.T) {
+	var got string
+	a := newTestApp(t, &Config{Name: "app", NoColor: true, PromptMissing: true}, &Command{
+		Name: "login",
+		Help: "log in",
+		Flags: func(f *Flags) {
+			f.String("u", "user", "", "the user name", FlagRequired())
+			f.String("p", "password", "", "the password", FlagRequired(), FlagPrompt(PromptOptions{Secret: true}))
+		},
+		Args: func(a *Args) {
+			a.String("server", "the server", ArgPrompt(PromptOptions{
+				Message: "server",
+				Choices: []string{"eu", "us"},
+			}))
+			a.Int("port", "the port", ArgPrompt(PromptOptions{Default: "443"}))
+			a.StringList("scopes", "the scopes", Default([]string{"all"}))
+		},
+		Run: func(c *Context) error {
+			got = fmt.Sprintf("%s:%s@%s:%d %v", c.Flags.String("user"), c.Flags.String("password"),
+				c.Args.String("server"), c.Args.Int("port"), c.Args.StringList("scopes"))
+			return nil
+		},
+	})
+	out := newScriptedShell(t, a,
+		"",       // user: empty is invalid
+		"alice",  // user
+		"secret", // password
+		"de",     // server: invalid choice
+		"2",      // server: choice number
+		"x",      // port: invalid int
+		"",       // port: default
+	)
+
+	if err := a.RunCommand([]string{"login"}); err != nil {
+		t.Fatal(err)
+	}
+	if got != "alice:secret@us:443 [all]" {
+		t.Errorf("unexpected values %q", got)
+	}
+
+	want := []string{
+		"error: a value is required",
+		"  1) eu\n  2) us",
+		"error: invalid choice 'de'",
+		"error: invalid int value 'x' for argument: port",
+	}
+	for _, w := range want {
+		if !strings.Contains(out.String(), w) {
+			t.Errorf("output misses %q:\n%s", w, out.String())
+		}
+	}
+
+	// Given values are not asked for.
+	newScriptedShell(t, a, "443")
+	if err := a.RunCommand([]string{"login", "-u", "bob", "-p", "pw", "eu"}); err != nil {
+		t.Fatal(err)
+	}
+	if got != "bob:pw@eu:443 [all]" {
+		t.Errorf("unexpected values %q", got)
+	}
+}
+
+func TestPromptMissingDisabled(t *testing.T) {
+	// Prompting is opt-in.
+	a := newTestApp(t, &Config{Name: "app", NoColor: true}, &Command{
+		Name: "login",
+		Help: "log in",
+		Flags: func(f *Flags) {
+			f.String("u", "user", "", "the user name", FlagRequired())
+			f.String("p", "password", "", "the password", FlagRequired())
+		},
+		Args: func(a *Args) {
+			a.String("server", "the server", ArgPrompt(PromptOptions{}))
+		},
+		Run: func(c *Context) error { return nil },
+	})
+	newScriptedShell(t, a, "alice")
+
+	err := a.RunCommand([]string{"login"})
+	var fe *FlagError
+	if !errors.As(err, &fe) || !errors.Is(err, ErrMissingFlag) || err.Error() != "missing required flag '--user'" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	err = a.RunCommand([]string{"login", "-u", "bob", "-p", "pw"})
+	var ae *ArgError
+	if !errors.As(err, &ae) || !errors.Is(err, ErrMissingArgument) || err.Error() != "missing argument 'server'" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// And only within the shell.
+	a = newTestApp(t, &Config{Name: "app", NoColor: true, PromptMissing: true}, &Command{
+		Name: "login",
+		Help: "log in",
+		Flags: func(f *Flags) {
+			f.String("u", "user", "", "the user name", FlagRequired())
+		},
+		Run: func(c *Context) error { return nil },
+	})
+	newScriptedShell(t, a, "alice")
+	a.isShell = false
+	if err = a.RunCommand([]string{"login"}); !errors.Is(err, ErrMissingFlag) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestPromptMissingParentFlags(t *testing.T) {
+	var got string
+	admin := &Command{
+		Name: "admin",
+		Help: "admin tools",
+		Flags: func(f *Flags) {
+			f.StringL("realm", "", "the realm", FlagRequired())
+		},
+	}
+	admin.AddCommand(&Command{
+		Name: "users",
+		Help: "list users",
+		Run: func(c *Context) error {
+			got = c.Flags.String("token") + "@" + c.Flags.String("realm")
+			return nil
+		},
+	})
+	a := newTestApp(t, &Config{
+		Name:          "app",
+		NoColor:       true,
+		PromptMissing: true,
+		Flags: func(f *Flags) {
+			f.StringL("token", "", "the api token", FlagRequired())
+		},
+	}, admin)
+
+	// The flags of the app and the parent commands are required.
+	err := a.RunCommand([]string{"admin", "--realm", "main", "users"})
+	if !errors.Is(err, ErrMissingFlag) || err.Error() != "missing required flag '--token'" {
+		t.Errorf("unexpected error %v", err)
+	}
+	a.flagMap["token"] = &FlagMapItem{Value: "t"}
+	err = a.RunCommand([]string{"admin", "users"})
+	if !errors.Is(err, ErrMissingFlag) || err.Error() != "missing required flag '--realm'" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// And asked for within the shell.
+	delete(a.flagMap, "token")
+	newScriptedShell(t, a, "secret", "main")
+	if err = a.RunCommand([]string{"admin", "users"}); err != nil {
+		t.Fatal(err)
+	}
+	if got != "secret@main" {
+		t.Errorf("unexpected values %q", got)
+	}
+}
+
+func TestPromptChoice(t *testing.T) {
+	po := PromptOptions{Choices: []string{"a", "b"}}
+
+	tests := map[string]string{"1": "a", "2": "b", "b": "b", "3": "3", "c": "c"}
+	for in, want := range tests {
+		if got, _ := po.choice(in); got != want {
+			t.Errorf("choice(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if err := po.validate("c"); err == nil {
+		t.Error("expected invalid choice error")
+	}
+
+	po.Validate = func(v string) error {
+		if v == "b" {
+			return fmt.Errorf("b is not allowed")
+		}
+		return nil
+	}
+	if !reflect.DeepEqual(po.validate("b"), fmt.Errorf("b is not allowed")) {
+		t.Error("expected validation error")
+	}
+}
@@ -0,0 +1,378 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	shlex "github.com/desertbit/go-shlex"
+	"github.com/fatih/color"
+)
+
+// SetVariable sets a shell variable, which is expanded in the
+// shell input with $name or ${name}.
+func (a *App) SetVariable(name, value string) {
+	if a.variables == nil {
+		a.variables = make(map[string]string)
+	}
+	a.variables[name] = value
+}
+
+// Variable returns the value of the shell variable.
+func (a *App) Variable(name string) (value string, ok bool) {
+	value, ok = a.variables[name]
+	return
+}
+
+// SetAlias sets an alias for the command line within the shell.
+// Arguments following the alias are appended to the line.
+func (a *App) SetAlias(name, line string) {
+	if a.aliases == nil {
+		a.aliases = make(map[string]string)
+	}
+	a.aliases[name] = line
+}
+
+// runLine expands the variables and aliases of the shell input line and
+// runs the command.
+func (a *App) runLine(line string) error {
+	args, err := a.lineArgs(line)
+	if err != nil {
+		return err
+	}
+	return a.RunCommand(args)
+}
+
+// lineArgs returns the args of the shell input line with the
+// variables and aliases expanded.
+func (a *App) lineArgs(line string) ([]string, error) {
+	args, err := a.splitLine(line)
+	if err != nil {
+		return nil, fmt.Errorf("invalid args: %v", err)
+	}
+
+	if len(args) > 0 {
+		if alias, ok := a.aliases[args[0]]; ok {
+			aliasArgs, err := a.splitLine(alias)
+			if err != nil {
+				return nil, fmt.Errorf("invalid alias '%s': %v", args[0], err)
+			}
+			args = append(aliasArgs, args[1:]...)
+		}
+	}
+	return args, nil
+}
+
+// splitLine splits the line into words like the command line and expands
+// $name and ${name} with the value of the shell variable. Environment variables
+// are only expanded, if enabled by the config. Unknown variables, single quoted
+// and escaped text are kept as they are. The values become part of their word
+// as they are, so they are neither split nor parsed again.
+func (a *App) splitLine(line string) ([]string, error) {
+	var (
+		words  []string
+		word   []rune
+		inWord bool
+		quote  rune
+		r      = []rune(line)
+	)
+	for i := 0; i < len(r); i++ {
+		c := r[i]
+		switch {
+		case quote == '\'':
+			if c == quote {
+				quote = 0
+			} else {
+				word = append(word, c)
+			}
+		case c == '\\':
+			if i+1 == len(r) {
+				return nil, shlex.ErrNoEscaped
+			}
+			i++
+			// Within double quotes, only quotes and backslashes are escaped.
+			if quote == '"' && r[i] != '"' && r[i] != '\\' {
+				word = append(word, c)
+			}
+			word = append(word, r[i])
+			inWord = true
+		case quote == '"' && c == quote:
+			quote = 0
+		case quote == 0 && (c == '\'' || c == '"'):
+			quote = c
+			inWord = true
+		case c == '$':
+			name, n := variableName(r[i+1:])
+			if n == 0 {
+				word = append(word, c)
+				inWord = true
+				continue
+			}
+			value, ok := a.variable(name)
+			if !ok {
+				word = append(word, r[i:i+n+1]...)
+				inWord = true
+				i += n
+				continue
+			}
+			// Empty values are dropped, unless quoted.
+			word = append(word, []rune(value)...)
+			inWord = inWord || quote != 0 || len(value) > 0
+			i += n
+		case quote == 0 && unicode.IsSpace(c):
+			if inWord {
+				words = append(words, string(word))
+				word, inWord = word[:0], false
+			}
+		default:
+			word = append(word, c)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, shlex.ErrNoClosing
+	} else if inWord {
+		words = append(words, string(word))
+	}
+	return words, nil
+}
+
+// variable returns the shell variable or, if enabled, the environment variable.
+func (a *App) variable(name string) (string, bool) {
+	if v, ok := a.variables[name]; ok {
+		return v, true
+	} else if a.config.ExpandEnv {
+		return os.LookupEnv(name)
+	}
+	return "", false
+}
+
+// variableName returns the name of the variable at the beginning of r
+// and the number of runes it occupies. Braces are optional.
+func variableName(r []rune) (name string, n int) {
+	braced := len(r) > 0 && r[0] == '{'
+	if braced {
+		n = 1
+	}
+	start := n
+	for n < len(r) && (r[n] == '_' || unicode.IsLetter(r[n]) || unicode.IsDigit(r[n])) {
+		n++
+	}
+	name = string(r[start:n])
+
+	if len(name) == 0 {
+		return "", 0
+	} else if braced {
+		if n >= len(r) || r[n] != '}' {
+			return "", 0
+		}
+		n++
+	}
+	return name, n
+}
+
+// rcFile returns the path of the rc file. Empty, if disabled.
+func (a *App) rcFile() string {
+	if norc, _ := Lookup[bool](a.flagMap, "norc"); norc || a.config.NoRCFile {
+		return ""
+	} else if len(a.config.RCFile) > 0 {
+		return a.config.RCFile
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, "."+a.config.Name+"rc")
+}
+
+// runRCFile executes the rc file, if it exists. Each line is either a
+// directive or a command. Errors are printed and the next line is executed.
+// The shell is started, even if the file can't be read.
+//
+//	# comment
+//	set name value
+//	alias name command line
+//	prompt text
+//	theme name|file
+//	color element attributes...
+//	command line
+func (a *App) runRCFile() {
+	path := a.rcFile()
+	if len(path) == 0 {
+		return
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return
+	} else if err != nil {
+		a.PrintError(err)
+		return
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		err = a.runRCLine(sc.Text())
+		if err != nil {
+			a.PrintError(fmt.Errorf("%s:%d: %w", path, n, err))
+		}
+	}
+	if err = sc.Err(); err != nil {
+		a.PrintError(fmt.Errorf("%s: %w", path, err))
+	}
+}
+
+// runRCLine executes a single line of the rc file.
+func (a *App) runRCLine(line string) error {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 || strings.HasPrefix(line, "#") {
+		return nil
+	}
+
+	directive, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+
+	switch directive {
+	case "set":
+		name, value, _ := strings.Cut(rest, " ")
+		if len(name) == 0 {
+			return fmt.Errorf("usage: set name value")
+		}
+		args, err := a.splitLine(value)
+		if err != nil {
+			return fmt.Errorf("invalid value of variable '%s': %v", name, err)
+		}
+		a.SetVariable(name, strings.Join(args, " "))
+
+	case "alias":
+		name, value, _ := strings.Cut(rest, " ")
+		if len(name) == 0 || len(strings.TrimSpace(value)) == 0 {
+			return fmt.Errorf("usage: alias name command")
+		}
+		a.SetAlias(name, strings.TrimSpace(value))
+
+	case "prompt":
+		args, err := a.splitLine(rest)
+		if err != nil || len(args) != 1 {
+			return fmt.Errorf("usage: prompt text")
+		}
+		a.config.Prompt = args[0]
+		a.SetDefaultPrompt()
+
+	case "theme":
+		if len(rest) == 0 {
+			return fmt.Errorf("usage: theme name|file")
+		}
+		t, ok := ThemeByName(rest)
+		if !ok {
+			var err error
+			t, err = LoadTheme(rest)
+			if err != nil {
+				return err
+			}
+		}
+		a.SetTheme(t)
+
+	case "color":
+		args := strings.Fields(rest)
+		if len(args) < 2 {
+			return fmt.Errorf("usage: color element attributes...")
+		}
+		c, err := parseColor(args[1:])
+		if err != nil {
+			return err
+		}
+		return a.setColor(args[0], c)
+
+	default:
+		return a.runLine(line)
+	}
+	return nil
+}
+
+// setColor sets the color of the UI element.
+func (a *App) setColor(element string, c *color.Color) error {
+	f := a.config.colorField(element)
+	if f == nil {
+		return fmt.Errorf("unknown color element '%s'", element)
+	}
+	*f = c
+	a.SetDefaultPrompt()
+	return nil
+}
+
+// colorAttributes maps the names of the color attributes.
+var colorAttributes = map[string]color.Attribute{
+	"bold":      color.Bold,
+	"faint":     color.Faint,
+	"italic":    color.Italic,
+	"underline": color.Underline,
+	"black":     color.FgBlack,
+	"red":       color.FgRed,
+	"green":     color.FgGreen,
+	"yellow":    color.FgYellow,
+	"blue":      color.FgBlue,
+	"magenta":   color.FgMagenta,
+	"cyan":      color.FgCyan,
+	"white":     color.FgWhite,
+	"hiblack":   color.FgHiBlack,
+	"hired":     color.FgHiRed,
+	"higreen":   color.FgHiGreen,
+	"hiyellow":  color.FgHiYellow,
+	"hiblue":    color.FgHiBlue,
+	"himagenta": color.FgHiMagenta,
+	"hicyan":    color.FgHiCyan,
+	"hiwhite":   color.FgHiWhite,
+}
+
+// parseColor returns the color with the named attributes, like "red bold".
+// Background colors are prefixed with "bg", like "bgblue".
+func parseColor(names []string) (*color.Color, error) {
+	attrs := make([]color.Attribute, 0, len(names))
+	for _, n := range names {
+		n = strings.ToLower(n)
+		bg := strings.HasPrefix(n, "bg")
+		if bg {
+			n = n[2:]
+		}
+
+		attr, ok := colorAttributes[n]
+		if !ok || (bg && attr < color.FgBlack) {
+			return nil, fmt.Errorf("unknown color '%s'", n)
+		} else if bg {
+			attr += color.BgBlack - color.FgBlack
+		}
+		attrs = append(attrs, attr)
+	}
+	return color.New(attrs...), nil
+}
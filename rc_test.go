@@ -0,0 +1,194 @@
+package grumble
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	shlex "github.com/desertbit/go-shlex"
+	"github.com/fatih/color"
+)
+
+// writeRCFile writes the rc file and returns its path.
+func writeRCFile(t *testing.T, rc string) string {
+	file := filepath.Join(t.TempDir(), "apprc")
+	err := os.WriteFile(file, []byte(rc), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// ---------------------------------------------------------------------------
+// TestRCFile
+// ---------------------------------------------------------------------------
+
+func TestRCFile(t *testing.T) {
+	rc := `
+# comment
+set name "the world"
+alias shout echo -u hello
+prompt "$name> "
+color error red bold
+echo hello $name
+shout ${name}
+unknown
+color nothing red
+`
+	var got []string
+	a := newTestApp(t, &Config{Name: "app", RCFile: writeRCFile(t, rc), NoColor: true}, &Command{
+		Name: "echo",
+		Help: "echo the args",
+		Flags: func(f *Flags) {
+			f.Bool("u", "upper", false, "upper case")
+		},
+		Args: func(a *Args) {
+			a.StringList("words", "the words")
+		},
+		Run: func(c *Context) error {
+			s := strings.Join(c.Args.StringList("words"), " ")
+			if c.Flags.Bool("upper") {
+				s = strings.ToUpper(s)
+			}
+			got = append(got, s)
+			return nil
+		},
+	})
+	out := newScriptedShell(t, a)
+	a.runRCFile()
+
+	if want := []string{"hello the world", "HELLO THE WORLD"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if v, ok := a.Variable("name"); !ok || v != "the world" {
+		t.Errorf("unexpected variable %q", v)
+	}
+	if a.currentPrompt != "the world> " {
+		t.Errorf("unexpected prompt %q", a.currentPrompt)
+	}
+	if !a.config.ErrorColor.Equals(color.New(color.FgRed, color.Bold)) {
+		t.Error("expected the error color to be set")
+	}
+	for _, w := range []string{"apprc:9: unknown command 'unknown'", "apprc:10: unknown color element 'nothing'"} {
+		if !strings.Contains(out.String(), w) {
+			t.Errorf("output misses %q:\n%s", w, out.String())
+		}
+	}
+}
+
+func TestRCFileDisabled(t *testing.T) {
+	var got []string
+	a := newTestApp(t, &Config{Name: "app", RCFile: writeRCFile(t, "echo\n"), NoColor: true}, &Command{
+		Name: "echo",
+		Help: "echo the args",
+		Run: func(c *Context) error {
+			got = append(got, c.Command.Name)
+			return nil
+		},
+	})
+	a.flagMap["norc"] = &FlagMapItem{Value: true}
+	if a.runRCFile(); len(got) != 0 {
+		t.Errorf("expected the rc file to be skipped: %q", got)
+	}
+
+	delete(a.flagMap, "norc")
+	a.config.RCFile = filepath.Join(t.TempDir(), "missing")
+	if a.runRCFile(); len(got) != 0 {
+		t.Errorf("expected a missing rc file to be skipped: %q", got)
+	}
+
+	// Read errors are printed.
+	out := newScriptedShell(t, a)
+	a.config.RCFile = t.TempDir()
+	if a.runRCFile(); !strings.Contains(out.String(), "is a directory") {
+		t.Errorf("expected a read error, got %q", out.String())
+	}
+
+	a = New(&Config{Name: "app"})
+	if home, err := os.UserHomeDir(); err == nil && a.rcFile() != filepath.Join(home, ".apprc") {
+		t.Errorf("unexpected default rc file %q", a.rcFile())
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestSplitLine
+// ---------------------------------------------------------------------------
+
+func TestSplitLine(t *testing.T) {
+	t.Setenv("GRUMBLE_TEST_ENV", "it's")
+	a := newTestApp(t, nil)
+	a.SetVariable("a", "x")
+	a.SetVariable("empty", "")
+	a.SetVariable("a_1", "y")
+	a.SetVariable("inject", `" --force '`)
+
+	tests := map[string][]string{
+		"$a $a_1 ${a}b":      {"x", "y", "xb"},
+		`"$a" '$a' \$a`:      {"x", "$a", "$a"},
+		`"a\"b\n" 'c\' d\ e`: {`a"b\n`, `c\`, "d e"},
+		`"" ''`:              {"", ""},
+		"$GRUMBLE_TEST_ENV":  {"$GRUMBLE_TEST_ENV"},
+		"$ ${a $unknown ${}": {"$", "${a", "$unknown", "${}"},
+		`"$unknown" ${b}c`:   {"$unknown", "${b}c"},
+		`$empty "$empty"`:    {""},
+		"echo $inject":       {"echo", `" --force '`},
+		`"$inject x"`:        {`" --force ' x`},
+	}
+	for in, want := range tests {
+		got, err := a.splitLine(in)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: expected %q, got %q %v", in, want, got, err)
+		}
+	}
+
+	for _, in := range []string{`"a`, "'a", `a\`} {
+		if _, err := a.splitLine(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+
+	// Environment variables are expanded, if enabled.
+	a.config.ExpandEnv = true
+	got, err := a.splitLine("$GRUMBLE_TEST_ENV $a $unknown")
+	if err != nil || !reflect.DeepEqual(got, []string{"it's", "x", "$unknown"}) {
+		t.Errorf("unexpected words %q %v", got, err)
+	}
+}
+
+func TestSplitLineUndefined(t *testing.T) {
+	t.Setenv("HOME", "/home/test")
+	a := newTestApp(t, nil)
+
+	// Lines without defined variables are split like by shlex.
+	for _, in := range []string{
+		`login --password pa$$word $HOME 'x$y'`,
+		`echo "$HOME/${dir}" \$x $ $$`,
+	} {
+		want, err := shlex.Split(in, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := a.splitLine(in)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: expected %q, got %q %v", in, want, got, err)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestParseColor
+// ---------------------------------------------------------------------------
+
+func TestParseColor(t *testing.T) {
+	c, err := parseColor([]string{"Red", "bold", "bgblue"})
+	if err != nil || !c.Equals(color.New(color.FgRed, color.Bold, color.BgBlue)) {
+		t.Errorf("unexpected color: %v", err)
+	}
+	for _, n := range []string{"pink", "bgbold"} {
+		if _, err := parseColor([]string{n}); err == nil {
+			t.Errorf("expected an error for %q", n)
+		}
+	}
+}
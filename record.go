@@ -0,0 +1,458 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Transcript formats.
+const (
+	// TranscriptText writes a plain text transcript. Input lines are prefixed
+	// with '$' and errors with '!', both followed by the timestamp.
+	// The output is indented.
+	TranscriptText = "text"
+
+	// TranscriptAsciinema writes an asciinema v2 recording, which can be played
+	// back with 'asciinema play'.
+	TranscriptAsciinema = "asciinema"
+)
+
+var ansiEscapes = regexp.MustCompile("\x1b\\[[0-9;?]*[a-zA-Z]")
+
+// recorder writes the shell session to a transcript file.
+type recorder struct {
+	mu    sync.Mutex
+	f     io.WriteCloser
+	cast  bool
+	start time.Time
+	now   func() time.Time
+	line  []byte // Pending output line of the text format.
+}
+
+// StartRecording records the shell input, output and errors to the transcript
+// file, which is overwritten. The format is TranscriptText or TranscriptAsciinema.
+// If empty, files with the .cast extension are written as asciinema recording.
+func (a *App) StartRecording(path, format string) error {
+	if format == "" {
+		format = TranscriptText
+		if filepath.Ext(path) == ".cast" {
+			format = TranscriptAsciinema
+		}
+	} else if format != TranscriptText && format != TranscriptAsciinema {
+		return fmt.Errorf("invalid transcript format '%s'", format)
+	}
+
+	err := a.StopRecording()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	r := &recorder{f: f, cast: format == TranscriptAsciinema, now: time.Now}
+	r.start = r.now()
+
+	if r.cast {
+		width, height := a.outputWidth(), 24
+		if f, ok := a.rlStdout().(*os.File); ok {
+			if _, h, err := term.GetSize(int(f.Fd())); err == nil {
+				height = h
+			}
+		}
+		err = r.writeEvent(map[string]interface{}{
+			"version":   2,
+			"width":     width,
+			"height":    height,
+			"timestamp": r.start.Unix(),
+			"title":     a.config.Name,
+		})
+	} else {
+		_, err = fmt.Fprintf(f, "# %s transcript started %s\n", a.config.Name, r.start.Format(time.RFC3339))
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	a.recorderMutex.Lock()
+	a.recorder = r
+	a.recorderMutex.Unlock()
+	return nil
+}
+
+// StopRecording stops the recording started with StartRecording
+// and closes the transcript file.
+func (a *App) StopRecording() error {
+	a.recorderMutex.Lock()
+	r := a.recorder
+	a.recorder = nil
+	a.recorderMutex.Unlock()
+
+	if r == nil {
+		return nil
+	}
+	return r.close()
+}
+
+// IsRecording returns true, if the shell session is recorded.
+func (a *App) IsRecording() bool {
+	a.recorderMutex.Lock()
+	defer a.recorderMutex.Unlock()
+	return a.recorder != nil
+}
+
+// Replay runs the commands of the transcript file in order, like they were
+// typed into the shell. Both transcript formats are supported. The commands
+// are printed with the prompt before they are run. Failed commands print
+// their error and the replay continues. Record commands are skipped, also
+// if they are abbreviated or run by an alias.
+func (a *App) Replay(path string) error {
+	lines, err := readTranscript(path)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		if a.IsClosing() {
+			break
+		}
+		if a.isRecordCommand(line) {
+			continue
+		}
+
+		a.Printf("%s%s\n", a.currentPrompt, line)
+		a.recordInput(line)
+		err = a.runLine(line)
+		if err != nil {
+			a.printLineError(err)
+		}
+	}
+	return nil
+}
+
+// isRecordCommand returns true, if the line runs the record command
+// or one of its sub commands.
+func (a *App) isRecordCommand(line string) bool {
+	args, err := a.lineArgs(line)
+	if err != nil {
+		return false
+	}
+	cmd, _, err := a.commands.FindCommand(args)
+	if err != nil || cmd == nil {
+		return false
+	}
+	for cmd.parent != nil {
+		cmd = cmd.parent
+	}
+	return cmd.isBuiltin && cmd.Name == "record"
+}
+
+// printLineError prints the error of the shell input line.
+// It is recorded as error and not as output.
+func (a *App) printLineError(err error) {
+	var b bytes.Buffer
+	a.fprintError(&b, err)
+
+	_, _ = a.terminalStdout().Write(b.Bytes())
+	a.recordError(err, b.String())
+}
+
+// recordInput records the shell input line, if recording.
+func (a *App) recordInput(line string) {
+	a.recorderMutex.Lock()
+	defer a.recorderMutex.Unlock()
+	if a.recorder != nil {
+		a.recorder.input(a.currentPrompt, line)
+	}
+}
+
+// recordError records the error of the input line with its printed
+// text, if recording.
+func (a *App) recordError(err error, printed string) {
+	a.recorderMutex.Lock()
+	defer a.recorderMutex.Unlock()
+	if a.recorder != nil {
+		a.recorder.error(err, printed)
+	}
+}
+
+// recordWriter returns the writer, which also writes to the transcript.
+func (a *App) recordWriter(w io.Writer) io.Writer {
+	a.recorderMutex.Lock()
+	defer a.recorderMutex.Unlock()
+	if a.recorder == nil {
+		return w
+	}
+	return &recordWriter{w: w, r: a.recorder}
+}
+
+// rlStdout returns the output of the readline instance.
+func (a *App) rlStdout() io.Writer {
+	if a.in != nil {
+		return a.in.Config.Stdout
+	}
+	return os.Stdout
+}
+
+// addRecordCommand adds the builtin record command to the shell.
+func (a *App) addRecordCommand() {
+	record := &Command{
+		Name:      "record",
+		Help:      "record the session to a transcript",
+		isBuiltin: true,
+	}
+	record.AddCommand(&Command{
+		Name: "start",
+		Help: "start recording to the transcript file",
+		Flags: func(f *Flags) {
+			f.String("f", "format", "", "the transcript format: text or asciinema (default by the file extension)")
+		},
+		Args: func(a *Args) {
+			a.String("file", "the transcript file")
+		},
+		Run: func(c *Context) error {
+			return a.StartRecording(c.Args.String("file"), c.Flags.String("format"))
+		},
+		isBuiltin: true,
+	})
+	record.AddCommand(&Command{
+		Name: "stop",
+		Help: "stop recording",
+		Run: func(c *Context) error {
+			if !a.IsRecording() {
+				return fmt.Errorf("not recording")
+			}
+			return a.StopRecording()
+		},
+		isBuiltin: true,
+	})
+	record.AddCommand(&Command{
+		Name: "replay",
+		Help: "run the commands of the transcript file",
+		Args: func(a *Args) {
+			a.File("file", "the transcript file", PathOptions{MustExist: true})
+		},
+		Run: func(c *Context) error {
+			return a.Replay(c.Args.String("file"))
+		},
+		isBuiltin: true,
+	})
+	a.AddCommand(record)
+}
+
+// readTranscript returns the input lines of the transcript file.
+func readTranscript(path string) (lines []string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		s     = bufio.NewScanner(f)
+		n     int
+		cast  bool
+		input strings.Builder
+	)
+	s.Buffer(nil, 1024*1024)
+	for s.Scan() {
+		n++
+		text := s.Text()
+
+		// The asciinema header is a JSON object. The input events are
+		// collected and split into lines, as they might be single keys.
+		if n == 1 && strings.HasPrefix(text, "{") {
+			var header struct {
+				Version int `json:"version"`
+			}
+			if err = json.Unmarshal([]byte(text), &header); err != nil || header.Version != 2 {
+				return nil, fmt.Errorf("%s: unsupported asciinema recording", path)
+			}
+			cast = true
+			continue
+		} else if cast {
+			var event []interface{}
+			if err = json.Unmarshal([]byte(text), &event); err != nil || len(event) != 3 {
+				return nil, fmt.Errorf("%s:%d: invalid event", path, n)
+			}
+			if code, _ := event[1].(string); code == "i" {
+				data, _ := event[2].(string)
+				input.WriteString(data)
+			}
+			continue
+		}
+
+		// The text input lines are prefixed with '$' and the timestamp.
+		if strings.HasPrefix(text, "$ ") {
+			fields := strings.SplitN(text, " ", 3)
+			if len(fields) == 3 {
+				lines = append(lines, fields[2])
+			}
+		}
+	}
+	if err = s.Err(); err != nil {
+		return nil, err
+	}
+
+	if cast {
+		for _, line := range strings.FieldsFunc(input.String(), func(r rune) bool {
+			return r == '\r' || r == '\n'
+		}) {
+			if line = strings.TrimSpace(line); len(line) > 0 {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines, nil
+}
+
+// input records the input line.
+// The asciinema format also shows it as output, like the terminal echo.
+func (r *recorder) input(prompt, line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cast {
+		r.writeOutput(prompt + line + "\n")
+		_ = r.writeEvent([]interface{}{r.elapsed(), "i", line + "\r"})
+		return
+	}
+	r.flushLine()
+	fmt.Fprintf(r.f, "$ %s %s\n", r.now().Format(time.RFC3339), line)
+}
+
+// error records the error of the last input line. The asciinema
+// format shows the printed error as output.
+func (r *recorder) error(err error, printed string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cast {
+		r.writeOutput(printed)
+		return
+	}
+	r.flushLine()
+	fmt.Fprintf(r.f, "! %s %v\n", r.now().Format(time.RFC3339), err)
+}
+
+// output records the output. The text format is written line by line
+// without colors.
+func (r *recorder) output(p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cast {
+		r.writeOutput(string(p))
+		return
+	}
+
+	r.line = append(r.line, p...)
+	for {
+		i := strings.IndexByte(string(r.line), '\n')
+		if i < 0 {
+			break
+		}
+		r.writeLine(string(r.line[:i]))
+		r.line = r.line[i+1:]
+	}
+}
+
+func (r *recorder) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.cast {
+		r.flushLine()
+		fmt.Fprintf(r.f, "# transcript stopped %s\n", r.now().Format(time.RFC3339))
+	}
+	return r.f.Close()
+}
+
+// flushLine writes the pending output line of the text format.
+func (r *recorder) flushLine() {
+	if len(r.line) > 0 {
+		r.writeLine(string(r.line))
+		r.line = r.line[:0]
+	}
+}
+
+func (r *recorder) writeLine(s string) {
+	// Lines updated in place, like progress bars, are recorded in their final state.
+	s = ansiEscapes.ReplaceAllString(s, "")
+	if i := strings.LastIndexByte(s, '\r'); i >= 0 {
+		s = s[i+1:]
+	}
+	fmt.Fprintf(r.f, "  %s\n", s)
+}
+
+// writeOutput writes an asciinema output event. Newlines are translated
+// like the terminal does.
+func (r *recorder) writeOutput(s string) {
+	s = strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+	_ = r.writeEvent([]interface{}{r.elapsed(), "o", s})
+}
+
+func (r *recorder) writeEvent(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = r.f.Write(append(b, '\n'))
+	return err
+}
+
+// elapsed returns the seconds since the recording started.
+func (r *recorder) elapsed() float64 {
+	return float64(r.now().Sub(r.start).Microseconds()) / 1e6
+}
+
+// recordWriter writes to the writer and the transcript.
+type recordWriter struct {
+	w io.Writer
+	r *recorder
+}
+
+func (w *recordWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if n > 0 {
+		w.r.output(p[:n])
+	}
+	return n, err
+}
@@ -0,0 +1,221 @@
+package grumble
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestRecord
+// ---------------------------------------------------------------------------
+
+func TestRecordText(t *testing.T) {
+	var runs []string
+	a := newTestApp(t, &Config{Name: "app", NoColor: true}, &Command{
+		Name: "hello",
+		Help: "say hello",
+		Args: func(a *Args) {
+			a.String("name", "the name", Default("world"))
+		},
+		Run: func(c *Context) error {
+			runs = append(runs, "hello "+c.Args.String("name"))
+			c.App.Printf("hello %s\n", c.Args.String("name"))
+			return nil
+		},
+	}, &Command{
+		Name: "fail",
+		Help: "fail",
+		Run: func(c *Context) error {
+			runs = append(runs, "fail")
+			return errors.New("failed")
+		},
+	})
+	a.addRecordCommand()
+	path := filepath.Join(t.TempDir(), "session.log")
+
+	newScriptedShell(t, a, "hello", "fail", "record stop", "hello bob")
+	if err := a.StartRecording(path, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.runShell(); err != nil {
+		t.Fatal(err)
+	}
+	if a.IsRecording() {
+		t.Error("recording was not stopped")
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := `\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\S*`
+	want := regexp.MustCompile(`^# app transcript started ` + ts + `
+\$ ` + ts + ` hello
+  hello world
+\$ ` + ts + ` fail
+! ` + ts + ` failed
+\$ ` + ts + ` record stop
+# transcript stopped ` + ts + `
+$`)
+	if !want.Match(b) {
+		t.Errorf("unexpected transcript:\n%s", b)
+	}
+
+	// The replay skips the record commands.
+	runs = nil
+	out := newScriptedShell(t, a)
+	if err = a.Replay(path); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(runs, ",") != "hello world,fail" {
+		t.Errorf("unexpected replay %q", runs)
+	}
+	if !strings.Contains(out.String(), "app » hello\nhello world\napp » fail\nerror: failed\n") {
+		t.Errorf("unexpected replay output:\n%s", out.String())
+	}
+}
+
+func TestRecordAsciinema(t *testing.T) {
+	var runs []string
+	a := newTestApp(t, &Config{Name: "app", NoColor: true}, &Command{
+		Name: "hello",
+		Help: "say hello",
+		Args: func(a *Args) {
+			a.String("name", "the name", Default("world"))
+		},
+		Run: func(c *Context) error {
+			runs = append(runs, "hello "+c.Args.String("name"))
+			c.App.Printf("hello %s\n", c.Args.String("name"))
+			return nil
+		},
+	}, &Command{
+		Name: "fail",
+		Help: "fail",
+		Run: func(c *Context) error {
+			runs = append(runs, "fail")
+			return errors.New("failed")
+		},
+	})
+	a.addRecordCommand()
+	path := filepath.Join(t.TempDir(), "session.cast")
+
+	newScriptedShell(t, a, "hello 'bob alice'", "fail")
+	if err := a.StartRecording(path, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.runShell(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.StopRecording(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+
+	var header map[string]interface{}
+	if err = json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	if header["version"] != 2.0 || header["width"] != 80.0 || header["title"] != "app" {
+		t.Errorf("unexpected header %v", header)
+	}
+
+	var events []string
+	for _, l := range lines[1:] {
+		var e []interface{}
+		if err = json.Unmarshal([]byte(l), &e); err != nil || len(e) != 3 {
+			t.Fatalf("invalid event %s", l)
+		}
+		events = append(events, e[1].(string)+" "+e[2].(string))
+	}
+	want := []string{
+		"o app » hello 'bob alice'\r\n",
+		"i hello 'bob alice'\r",
+		"o hello bob alice\r\n",
+		"o app » fail\r\n",
+		"i fail\r",
+		"o error: failed\r\n",
+	}
+	if strings.Join(events, "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected events:\n%q\nwant:\n%q", events, want)
+	}
+
+	runs = nil
+	newScriptedShell(t, a)
+	if err = a.Replay(path); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(runs, ",") != "hello bob alice,fail" {
+		t.Errorf("unexpected replay %q", runs)
+	}
+}
+
+func TestRecordReplaySkipsRecord(t *testing.T) {
+	var runs []string
+	a := newTestApp(t, &Config{Name: "app", NoColor: true}, &Command{
+		Name: "hello",
+		Help: "say hello",
+		Args: func(a *Args) {
+			a.String("name", "the name", Default("world"))
+		},
+		Run: func(c *Context) error {
+			runs = append(runs, "hello "+c.Args.String("name"))
+			c.App.Printf("hello %s\n", c.Args.String("name"))
+			return nil
+		},
+	}, &Command{
+		Name: "fail",
+		Help: "fail",
+		Run: func(c *Context) error {
+			runs = append(runs, "fail")
+			return errors.New("failed")
+		},
+	})
+	a.addRecordCommand()
+	a.commands.SetAbbreviations(true)
+
+	path := filepath.Join(t.TempDir(), "session.log")
+	a.SetAlias("again", "record replay "+path)
+	err := os.WriteFile(path, []byte("$ 2026-10-19T10:00:00Z hello\n$ 2026-10-19T10:00:01Z rec rep "+path+
+		"\n$ 2026-10-19T10:00:02Z again\n$ 2026-10-19T10:00:03Z fail\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newScriptedShell(t, a)
+	if err = a.Replay(path); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(runs, ",") != "hello world,fail" {
+		t.Errorf("unexpected replay %q", runs)
+	}
+}
+
+func TestRecordErrors(t *testing.T) {
+	a := newTestApp(t, &Config{Name: "app", NoColor: true})
+	a.addRecordCommand()
+
+	if err := a.StartRecording(filepath.Join(t.TempDir(), "session"), "html"); err == nil || err.Error() != "invalid transcript format 'html'" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := a.RunCommand([]string{"record", "stop"}); err == nil || err.Error() != "not recording" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "invalid.cast")
+	if err := os.WriteFile(path, []byte(`{"version": 1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Replay(path); err == nil {
+		t.Error("expected unsupported recording error")
+	}
+}
@@ -25,65 +25,58 @@
 package cmd
 
 import (
-	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/desertbit/grumble"
-	"gopkg.in/AlecAivazis/survey.v1"
 )
 
 func init() {
 	App.AddCommand(&grumble.Command{
 		Name: "ask",
 		Help: "ask the user for foo",
-		Run: func(c *grumble.Context) error {
-			ask()
-			return nil
-		},
+		Run:  ask,
 	})
 }
 
-// the questions to ask
-var qs = []*survey.Question{
-	{
-		Name:      "name",
-		Prompt:    &survey.Input{Message: "What is your name?"},
-		Validate:  survey.Required,
-		Transform: survey.Title,
-	},
-	{
-		Name: "color",
-		Prompt: &survey.Select{
-			Message: "Choose a color:",
-			Options: []string{"red", "blue", "green"},
-			Default: "red",
-		},
-	},
-	{
-		Name:   "age",
-		Prompt: &survey.Input{Message: "How old are you?"},
-	},
-}
+func ask(c *grumble.Context) error {
+	_, err := c.Password("Please type your password")
+	if err != nil {
+		return err
+	}
 
-func ask() {
-	password := ""
-	prompt := &survey.Password{
-		Message: "Please type your password",
+	name, err := c.Input(grumble.PromptOptions{Message: "What is your name?"})
+	if err != nil {
+		return err
 	}
-	survey.AskOne(prompt, &password, nil)
 
-	// the answers will be written to this struct
-	answers := struct {
-		Name          string // survey will match the question and field names
-		FavoriteColor string `survey:"color"` // or you can tag fields to match a specific name
-		Age           int    // if the types don't match exactly, survey will try to convert for you
-	}{}
+	color, err := c.Select("Choose a color", []string{"red", "blue", "green"}, "red")
+	if err != nil {
+		return err
+	}
 
-	// perform the questions
-	err := survey.Ask(qs, &answers)
+	_, err = c.Input(grumble.PromptOptions{
+		Message:    "How old are you?",
+		AllowEmpty: true,
+		Validate: func(v string) error {
+			_, err := strconv.Atoi(v)
+			return err
+		},
+	})
 	if err != nil {
-		fmt.Println(err.Error())
-		return
+		return err
+	}
+
+	toppings, err := c.MultiSelect("Choose your toppings", []string{"cheese", "ham", "olives", "pineapple"}, []string{"cheese"})
+	if err != nil {
+		return err
+	}
+
+	ok, err := c.Confirm("Order the pizza?", true)
+	if err != nil || !ok {
+		return err
 	}
 
-	fmt.Printf("%s chose %s.", answers.Name, answers.FavoriteColor)
+	c.App.Printf("%s chose %s with %s.\n", name, color, strings.Join(toppings, ", "))
+	return nil
 }
@@ -0,0 +1,216 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Schema describes the app and its command tree.
+// It can be serialized to JSON.
+type Schema struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Flags       []FlagSchema    `json:"flags,omitempty"`
+	Commands    []CommandSchema `json:"commands,omitempty"`
+}
+
+// CommandSchema describes a command and its sub commands.
+type CommandSchema struct {
+	Name string `json:"name"`
+
+	// Path contains the names from the top-level command down to this command.
+	Path []string `json:"path"`
+
+	Aliases    []string        `json:"aliases,omitempty"`
+	Help       string          `json:"help"`
+	LongHelp   string          `json:"longHelp,omitempty"`
+	Usage      string          `json:"usage,omitempty"`
+	HelpGroup  string          `json:"helpGroup,omitempty"`
+	Examples   string          `json:"examples,omitempty"`
+	SeeAlso    []string        `json:"seeAlso,omitempty"`
+	Hidden     bool            `json:"hidden,omitempty"`
+	Deprecated string          `json:"deprecated,omitempty"`
+	Builtin    bool            `json:"builtin,omitempty"`
+	Runnable   bool            `json:"runnable"`
+	Flags      []FlagSchema    `json:"flags,omitempty"`
+	Args       []ArgSchema     `json:"args,omitempty"`
+	Commands   []CommandSchema `json:"commands,omitempty"`
+}
+
+// FlagSchema describes a flag.
+type FlagSchema struct {
+	Short string `json:"short,omitempty"`
+	Long  string `json:"long"`
+	Help  string `json:"help"`
+
+	// Type is the value type shown in the help, e.g. "int".
+	Type    string      `json:"type"`
+	Default interface{} `json:"default,omitempty"`
+
+	// ValueRequired is false for flags, which can be passed without a value.
+	ValueRequired bool `json:"valueRequired"`
+
+	// Required is true, if the flag must be set.
+	Required bool `json:"required,omitempty"`
+
+	Hidden     bool        `json:"hidden,omitempty"`
+	Deprecated string      `json:"deprecated,omitempty"`
+	Path       *PathSchema `json:"path,omitempty"`
+}
+
+// ArgSchema describes an argument.
+type ArgSchema struct {
+	Name string `json:"name"`
+	Help string `json:"help"`
+
+	// Type is the value type shown in the help, e.g. "int list".
+	Type     string      `json:"type"`
+	Default  interface{} `json:"default,omitempty"`
+	Optional bool        `json:"optional"`
+	List     bool        `json:"list"`
+
+	// ListMin and ListMax are the bounds of list arguments.
+	// They are 0, if the list is unbounded.
+	ListMin int `json:"listMin,omitempty"`
+	ListMax int `json:"listMax,omitempty"`
+
+	Path *PathSchema `json:"path,omitempty"`
+}
+
+// PathSchema describes the constraints of a path flag or argument.
+type PathSchema struct {
+	// Kind is either "any", "file" or "dir".
+	Kind       string   `json:"kind"`
+	Extensions []string `json:"extensions,omitempty"`
+	MustExist  bool     `json:"mustExist,omitempty"`
+}
+
+// Schema returns the description of the app and all its commands.
+// Builtin commands are only included, after the app has been started.
+func (a *App) Schema() *Schema {
+	return &Schema{
+		Name:        a.config.Name,
+		Description: a.config.Description,
+		Flags:       flagSchemas(&a.flags),
+		Commands:    commandSchemas(&a.commands, nil),
+	}
+}
+
+func commandSchemas(cmds *Commands, path []string) []CommandSchema {
+	schemas := make([]CommandSchema, 0, len(cmds.list))
+	for _, c := range cmds.list {
+		p := append(append([]string{}, path...), c.Name)
+		schemas = append(schemas, CommandSchema{
+			Name:       c.Name,
+			Path:       p,
+			Aliases:    c.Aliases,
+			Help:       c.Help,
+			LongHelp:   c.LongHelp,
+			Usage:      c.Usage,
+			HelpGroup:  c.HelpGroup,
+			Examples:   c.Examples,
+			SeeAlso:    c.SeeAlso,
+			Hidden:     c.Hidden,
+			Deprecated: c.Deprecated,
+			Builtin:    c.isBuiltin,
+			Runnable:   c.Run != nil,
+			Flags:      flagSchemas(&c.flags),
+			Args:       argSchemas(&c.args),
+			Commands:   commandSchemas(&c.commands, p),
+		})
+	}
+	return schemas
+}
+
+func flagSchemas(flags *Flags) []FlagSchema {
+	schemas := make([]FlagSchema, 0, len(flags.list))
+	for _, fi := range flags.list {
+		schemas = append(schemas, FlagSchema{
+			Short:         fi.Short,
+			Long:          fi.Long,
+			Help:          fi.Help,
+			Type:          fi.HelpArgs,
+			Default:       schemaValue(fi.Default),
+			ValueRequired: !fi.allowEmptyValue,
+			Required:      fi.required,
+			Hidden:        fi.isHidden,
+			Deprecated:    fi.deprecated,
+			Path:          pathSchema(fi.path, pathAny),
+		})
+	}
+	return schemas
+}
+
+func argSchemas(args *Args) []ArgSchema {
+	schemas := make([]ArgSchema, 0, len(args.list))
+	for _, ai := range args.list {
+		s := ArgSchema{
+			Name:     ai.Name,
+			Help:     ai.Help,
+			Type:     ai.HelpArgs,
+			Default:  schemaValue(ai.Default),
+			Optional: ai.optional,
+			List:     ai.isList,
+			Path:     pathSchema(ai.path, ai.pathKind),
+		}
+		if ai.isList {
+			s.ListMin = max(ai.listMin, 0)
+			s.ListMax = max(ai.listMax, 0)
+		}
+		schemas = append(schemas, s)
+	}
+	return schemas
+}
+
+func pathSchema(po *PathOptions, kind pathKind) *PathSchema {
+	if po == nil {
+		return nil
+	}
+	return &PathSchema{
+		Kind:       kind.String(),
+		Extensions: po.Extensions,
+		MustExist:  po.MustExist,
+	}
+}
+
+// schemaValue converts the value to a readable JSON value.
+func schemaValue(v interface{}) interface{} {
+	if d, ok := v.(time.Duration); ok {
+		return d.String()
+	}
+	return v
+}
+
+// printSchema prints the app schema as indented JSON.
+func (a *App) printSchema() error {
+	data, err := json.MarshalIndent(a.Schema(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = a.Printf("%s\n", data)
+	return err
+}
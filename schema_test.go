@@ -0,0 +1,91 @@
+package grumble
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// TestSchema
+// ---------------------------------------------------------------------------
+
+func TestSchema(t *testing.T) {
+	a := New(&Config{Name: "app", Description: "the app"})
+
+	admin := &Command{Name: "admin", Help: "admin tools", Hidden: true}
+	admin.AddCommand(&Command{
+		Name:    "users",
+		Aliases: []string{"u"},
+		Help:    "list users",
+		Flags: func(f *Flags) {
+			f.Duration("t", "timeout", time.Second, "the timeout")
+			f.PathL("export", "", "export file", PathOptions{Extensions: []string{".csv"}})
+		},
+		Args: func(a *Args) {
+			a.File("config", "the config", PathOptions{MustExist: true})
+			a.StringList("names", "the user names", Min(1), Max(3))
+		},
+		Run: func(c *Context) error { return nil },
+	})
+	a.AddCommand(admin)
+
+	s := a.Schema()
+	if s.Name != "app" || s.Description != "the app" || len(s.Flags) != 3 || len(s.Commands) != 1 {
+		t.Fatalf("unexpected schema %+v", s)
+	}
+
+	as := s.Commands[0]
+	if as.Name != "admin" || !as.Hidden || as.Runnable || len(as.Commands) != 1 {
+		t.Fatalf("unexpected admin schema %+v", as)
+	}
+
+	us := as.Commands[0]
+	if !reflect.DeepEqual(us.Path, []string{"admin", "users"}) || !reflect.DeepEqual(us.Aliases, []string{"u"}) || !us.Runnable {
+		t.Errorf("unexpected users schema %+v", us)
+	}
+
+	flags := make(map[string]FlagSchema)
+	for _, f := range us.Flags {
+		flags[f.Long] = f
+	}
+	if f := flags["timeout"]; f.Short != "t" || f.Type != "duration" || f.Default != "1s" || !f.ValueRequired {
+		t.Errorf("unexpected timeout flag %+v", f)
+	}
+	if f := flags["help"]; f.Type != "bool" || f.Default != false || f.ValueRequired {
+		t.Errorf("unexpected help flag %+v", f)
+	}
+	if f := flags["export"]; f.Path == nil || f.Path.Kind != "any" || !reflect.DeepEqual(f.Path.Extensions, []string{".csv"}) {
+		t.Errorf("unexpected export flag %+v", f)
+	}
+
+	if len(us.Args) != 2 {
+		t.Fatalf("unexpected args %+v", us.Args)
+	}
+	if arg := us.Args[0]; arg.Name != "config" || arg.Optional || arg.Path == nil || arg.Path.Kind != "file" || !arg.Path.MustExist {
+		t.Errorf("unexpected config arg %+v", arg)
+	}
+	if arg := us.Args[1]; !arg.List || arg.ListMin != 1 || arg.ListMax != 3 || arg.Type != "string list" {
+		t.Errorf("unexpected names arg %+v", arg)
+	}
+}
+
+func TestSchemaJSON(t *testing.T) {
+	a := New(&Config{Name: "app"})
+	a.AddCommand(&Command{Name: "status", Help: "show status", Run: func(c *Context) error { return nil }})
+
+	out := captureStdout(t, func() {
+		if err := a.printSchema(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	var s Schema
+	if err := json.Unmarshal([]byte(out), &s); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, out)
+	}
+	if s.Name != "app" || len(s.Commands) != 1 || s.Commands[0].Name != "status" || !s.Commands[0].Runnable {
+		t.Errorf("unexpected schema %+v", s)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the maximum number of suggestions for an unknown name.
+const maxSuggestions = 3
+
+// suggestions returns the candidates similar to the name. A candidate is
+// similar, if it starts with the name or its case-insensitive edit distance
+// does not exceed maxDistance. The distance is limited to half the name length,
+// so short names are not similar to everything. The closest candidates are
+// returned first. A negative maxDistance disables the suggestions.
+func suggestions(name string, candidates []string, maxDistance int) []string {
+	if maxDistance < 0 || len(name) == 0 {
+		return nil
+	}
+	maxDistance = min(maxDistance, max(1, len([]rune(name))/2))
+
+	type suggestion struct {
+		value    string
+		distance int
+	}
+
+	var (
+		found []suggestion
+		seen  = make(map[string]bool)
+	)
+	for _, c := range candidates {
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+
+		d := levenshtein(strings.ToLower(name), strings.ToLower(c))
+		if d <= maxDistance || strings.HasPrefix(c, name) {
+			found = append(found, suggestion{value: c, distance: d})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].distance < found[j].distance
+	})
+
+	var s []string
+	for i := 0; i < len(found) && i < maxSuggestions; i++ {
+		s = append(s, found[i].value)
+	}
+	return s
+}
+
+// didYouMean formats the suggestions to a question.
+// Returns an empty string, if there are no suggestions.
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+
+	quoted := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		quoted[i] = fmt.Sprintf("'%s'", s)
+	}
+
+	s := quoted[len(quoted)-1]
+	if len(quoted) > 1 {
+		s = strings.Join(quoted[:len(quoted)-1], ", ") + " or " + s
+	}
+	return fmt.Sprintf("did you mean %s?", s)
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(br)]
+}
@@ -0,0 +1,132 @@
+package grumble
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ----------------------------------------------------------------------------
+// TestSuggestions
+// ----------------------------------------------------------------------------
+
+func TestSuggestions(t *testing.T) {
+	candidates := []string{"status", "start", "stop", "list", "ls", "remove"}
+
+	tests := []struct {
+		name        string
+		maxDistance int
+		want        []string
+	}{
+		{"stauts", 2, []string{"status", "start"}},
+		{"stat", 2, []string{"start", "status", "stop"}},
+		{"lst", 2, []string{"list", "ls"}},
+		{"LIST", 2, []string{"list", "ls"}},
+		{"ad", 2, nil},
+		{"remov", 2, []string{"remove"}},
+		{"xyz", 2, nil},
+		{"stauts", 1, nil},
+		{"stauts", -1, nil},
+		{"", 2, nil},
+	}
+
+	for _, tt := range tests {
+		got := suggestions(tt.name, candidates, tt.maxDistance)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("suggestions(%q, %d) = %v, want %v", tt.name, tt.maxDistance, got, tt.want)
+		}
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"a"}, "did you mean 'a'?"},
+		{[]string{"a", "b"}, "did you mean 'a' or 'b'?"},
+		{[]string{"a", "b", "c"}, "did you mean 'a', 'b' or 'c'?"},
+	}
+
+	for _, tt := range tests {
+		if got := didYouMean(tt.in); got != tt.want {
+			t.Errorf("didYouMean(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"status", "stauts", 2},
+		{"über", "uber", 1},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// TestAppSuggestions
+// ----------------------------------------------------------------------------
+
+func TestAppSuggestions(t *testing.T) {
+	remote := &Command{Name: "remote", Help: "manage remotes"}
+	remote.AddCommand(&Command{Name: "add", Help: "add a remote", Run: func(c *Context) error { return nil }})
+	remote.AddCommand(&Command{Name: "remove", Aliases: []string{"rm"}, Help: "remove a remote", Run: func(c *Context) error { return nil }})
+
+	a := newTestApp(t, nil, remote, &Command{
+		Name: "status",
+		Help: "show the status",
+		Flags: func(f *Flags) {
+			f.Bool("v", "verbose", false, "verbose output")
+		},
+		Run: func(c *Context) error { return nil },
+	})
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"stauts"}, "unknown command 'stauts', did you mean 'status'?"},
+		{[]string{"xyz"}, "unknown command 'xyz', try 'help'"},
+		{[]string{"remote", "ad"}, "unknown sub command 'ad' of 'remote', did you mean 'add'?"},
+		{[]string{"remote", "rn"}, "unknown sub command 'rn' of 'remote', did you mean 'rm'?"},
+		{[]string{"status", "--verbos"}, "invalid flag: --verbos, did you mean '--verbose'?"},
+		{[]string{"status", "--xyz"}, "invalid flag: --xyz"},
+	}
+
+	for _, tt := range tests {
+		err := a.RunCommand(tt.args)
+		if err == nil {
+			t.Errorf("%v: expected an error", tt.args)
+			continue
+		}
+		if err.Error() != tt.want {
+			t.Errorf("%v: got error %q, want %q", tt.args, err.Error(), tt.want)
+		}
+	}
+}
+
+func TestAppSuggestionsDisabled(t *testing.T) {
+	a := newTestApp(t, &Config{SuggestionDistance: -1}, &Command{
+		Name: "status",
+		Help: "show the status",
+		Run:  func(c *Context) error { return nil },
+	})
+
+	err := a.RunCommand([]string{"stauts"})
+	if err == nil || strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("expected an error without suggestion, got %v", err)
+	}
+}
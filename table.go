@@ -0,0 +1,204 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/desertbit/columnize"
+	"github.com/fatih/color"
+)
+
+// tableGlue separates the table columns.
+const tableGlue = "  "
+
+// cellMarker fills the placeholder of a cell passed to columnize.
+const cellMarker = "\x00"
+
+// Align defines the alignment of a table column.
+type Align int
+
+const (
+	// AlignLeft aligns the cells to the left.
+	AlignLeft Align = iota
+
+	// AlignRight aligns the cells to the right, e.g. for numbers.
+	AlignRight
+
+	// AlignCenter centers the cells.
+	AlignCenter
+)
+
+// Column defines a table column.
+type Column struct {
+	// Header of the column.
+	Header string
+
+	// Align defines the alignment of the cells.
+	Align Align
+
+	// MaxWidth truncates longer cells. Zero does not limit the width.
+	MaxWidth int
+
+	// Color of the cells. The header uses the table header color.
+	Color *color.Color
+}
+
+// Table writes rows aligned in columns. The columns are aligned by the display
+// width of the cells, so wide characters and colored cells are supported.
+type Table struct {
+	a       *App
+	out     io.Writer
+	columns []Column
+	rows    [][]string
+}
+
+// Table returns a new table with the columns. The header row is
+// omitted, if all headers are empty.
+func (a *App) Table(columns ...Column) *Table {
+	return &Table{a: a, out: a, columns: columns}
+}
+
+// Table returns a new table with the columns writing to the command output.
+// See App.Table.
+func (c *Context) Table(columns ...Column) *Table {
+	return &Table{a: c.App, out: c, columns: columns}
+}
+
+// AddRow adds a row. The cells are formatted with fmt.Sprint.
+// Missing cells are empty and additional cells are ignored.
+func (t *Table) AddRow(cells ...interface{}) *Table {
+	row := make([]string, len(t.columns))
+	for i := range row {
+		if i < len(cells) {
+			row[i] = fmt.Sprint(cells[i])
+		}
+	}
+	t.rows = append(t.rows, row)
+	return t
+}
+
+// Render writes the table to the output.
+func (t *Table) Render() error {
+	_, err := t.out.Write([]byte(t.String()))
+	return err
+}
+
+// String returns the formatted table.
+func (t *Table) String() string {
+	rows := make([][]string, 0, len(t.rows)+1)
+	header := false
+	for _, c := range t.columns {
+		if len(c.Header) > 0 {
+			header = true
+			break
+		}
+	}
+	if header {
+		h := make([]string, len(t.columns))
+		for i, c := range t.columns {
+			h[i] = c.Header
+		}
+		rows = append(rows, h)
+	}
+	rows = append(rows, t.rows...)
+
+	if len(rows) == 0 {
+		return ""
+	}
+
+	// Truncate and color the cells and determine the column widths.
+	widths := make([]int, len(t.columns))
+	for r, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cell = truncate(strings.ReplaceAll(cell, cellMarker, ""), t.columns[i].MaxWidth)
+			widths[i] = max(widths[i], textWidth(cell))
+
+			c := t.columns[i].Color
+			if header && r == 0 {
+				c = t.a.config.TableHeaderColor
+			}
+			cells[i] = t.a.colorize(c, cell)
+		}
+		rows[r] = cells
+	}
+
+	// Columnize pads by the byte length. Align placeholders with the display
+	// width of the cells instead and replace them afterwards.
+	config := columnize.DefaultConfig()
+	config.Delim = "|"
+	config.Glue = tableGlue
+
+	lines := make([]string, len(rows))
+	for r, row := range rows {
+		placeholders := make([]string, len(row))
+		for i, cell := range row {
+			if t.columns[i].Align != AlignLeft {
+				row[i] = pad(cell, widths[i], t.columns[i].Align)
+			}
+			placeholders[i] = strings.Repeat(cellMarker, textWidth(row[i]))
+		}
+		lines[r] = strings.Join(placeholders, "|")
+	}
+
+	var b strings.Builder
+	for r, l := range strings.Split(columnize.Format(lines, config), "\n") {
+		for _, cell := range rows[r] {
+			if w := textWidth(cell); w > 0 {
+				l = strings.Replace(l, strings.Repeat(cellMarker, w), cell, 1)
+			}
+		}
+		b.WriteString(strings.TrimRight(l, " ") + "\n")
+	}
+	return b.String()
+}
+
+// pad the text to the width with the alignment.
+func pad(s string, width int, align Align) string {
+	n := width - textWidth(s)
+	if n <= 0 {
+		return s
+	}
+
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", n) + s
+	case AlignCenter:
+		return strings.Repeat(" ", n/2) + s + strings.Repeat(" ", n-n/2)
+	default:
+		return s + strings.Repeat(" ", n)
+	}
+}
+
+// colorize the text, if colors are enabled.
+func (a *App) colorize(c *color.Color, s string) string {
+	if a.config.NoColor || c == nil {
+		return s
+	}
+	return c.Sprint(s)
+}
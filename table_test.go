@@ -0,0 +1,72 @@
+package grumble
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// ---------------------------------------------------------------------------
+// TestTable
+// ---------------------------------------------------------------------------
+
+func TestTable(t *testing.T) {
+	a := newTestApp(t, &Config{Name: "app", NoColor: true})
+
+	tb := a.Table(
+		Column{Header: "NAME", MaxWidth: 8},
+		Column{Header: "CPU", Align: AlignRight},
+		Column{Header: "ZONE", Align: AlignCenter},
+	)
+	tb.AddRow("api", 12, "eu")
+	tb.AddRow("frontend-web", 3, "us-east")
+	tb.AddRow("日本語", 100)
+
+	want := "" +
+		"NAME      CPU   ZONE\n" +
+		"api        12    eu\n" +
+		"fronten…    3  us-east\n" +
+		"日本語    100\n"
+	if got := tb.String(); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	// Rendering does not change the rows.
+	if tb.rows[1][0] != "frontend-web" {
+		t.Errorf("unexpected row %q", tb.rows[1])
+	}
+
+	b := newScriptedShell(t, a)
+	if err := tb.Render(); err != nil || b.String() != want {
+		t.Errorf("unexpected rendered table %q: %v", b.String(), err)
+	}
+}
+
+func TestTableColors(t *testing.T) {
+	red := color.New(color.FgRed)
+	red.EnableColor()
+
+	tb := New(&Config{Name: "app"}).Table(Column{Color: red}, Column{})
+	tb.AddRow("a", "x")
+	tb.AddRow("bbb", "y")
+
+	want := red.Sprint("a") + "    x\n" + red.Sprint("bbb") + "  y\n"
+	if got := tb.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	tb.a.config.NoColor = true
+	if got := tb.String(); got != "a    x\nbbb  y\n" {
+		t.Errorf("expected no colors, got %q", got)
+	}
+
+	// Colored wide characters are aligned by their display width.
+	tb = New(&Config{Name: "app"}).Table(Column{Color: red, Align: AlignRight}, Column{})
+	tb.AddRow("日本", "x")
+	tb.AddRow("a", "y")
+
+	want = red.Sprint("日本") + "  x\n   " + red.Sprint("a") + "  y\n"
+	if got := tb.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,302 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// themeElements are the names of the colored UI elements
+// used in theme files and the rc file.
+var themeElements = []string{
+	"prompt", "multiprompt", "logo", "error", "warning", "headline", "command",
+	"flag", "string", "variable", "autosuggestion", "completion", "tableheader",
+}
+
+// Theme defines the colors of all UI elements.
+// Nil colors are not changed, if the theme is applied.
+type Theme struct {
+	Prompt       *color.Color
+	MultiPrompt  *color.Color
+	ASCIILogo    *color.Color
+	Error        *color.Color
+	Warning      *color.Color
+	HelpHeadline *color.Color
+
+	// Syntax highlighting and autosuggestions.
+	Command        *color.Color
+	Flag           *color.Color
+	String         *color.Color
+	Variable       *color.Color
+	Autosuggestion *color.Color
+
+	// Completion colors the descriptions of the completion candidates.
+	Completion *color.Color
+
+	// TableHeader colors the header row of tables.
+	TableHeader *color.Color
+}
+
+// DefaultTheme returns the default colors.
+func DefaultTheme() *Theme {
+	return &Theme{
+		Prompt:         color.New(color.FgYellow, color.Bold),
+		Error:          color.New(color.FgRed, color.Bold),
+		Warning:        color.New(color.FgYellow, color.Bold),
+		Command:        color.New(color.FgGreen),
+		Flag:           color.New(color.FgCyan),
+		String:         color.New(color.FgYellow),
+		Variable:       color.New(color.FgMagenta),
+		Autosuggestion: color.New(color.FgHiBlack),
+	}
+}
+
+// DarkTheme returns bright colors for dark terminals.
+func DarkTheme() *Theme {
+	return &Theme{
+		Prompt:         color.New(color.FgHiCyan, color.Bold),
+		Error:          color.New(color.FgHiRed, color.Bold),
+		Warning:        color.New(color.FgHiYellow, color.Bold),
+		HelpHeadline:   color.New(color.FgHiWhite, color.Bold),
+		Command:        color.New(color.FgHiGreen),
+		Flag:           color.New(color.FgHiCyan),
+		String:         color.New(color.FgHiYellow),
+		Variable:       color.New(color.FgHiMagenta),
+		Autosuggestion: color.New(color.FgHiBlack),
+		Completion:     color.New(color.FgHiBlack),
+		TableHeader:    color.New(color.FgHiWhite, color.Bold),
+	}
+}
+
+// LightTheme returns dark colors for light terminals.
+func LightTheme() *Theme {
+	return &Theme{
+		Prompt:         color.New(color.FgBlue, color.Bold),
+		Error:          color.New(color.FgRed, color.Bold),
+		Warning:        color.New(color.FgMagenta, color.Bold),
+		HelpHeadline:   color.New(color.FgBlue, color.Bold),
+		Command:        color.New(color.FgBlue),
+		Flag:           color.New(color.FgMagenta),
+		String:         color.New(color.FgGreen),
+		Variable:       color.New(color.FgCyan),
+		Autosuggestion: color.New(color.Faint),
+		Completion:     color.New(color.Faint),
+		TableHeader:    color.New(color.Bold),
+	}
+}
+
+// MonochromeTheme returns a theme without colors, which only uses text attributes.
+func MonochromeTheme() *Theme {
+	return &Theme{
+		Prompt:         color.New(color.Bold),
+		Error:          color.New(color.Bold),
+		Warning:        color.New(color.Bold),
+		HelpHeadline:   color.New(color.Bold, color.Underline),
+		Command:        color.New(color.Bold),
+		Flag:           color.New(color.Italic),
+		String:         color.New(color.Underline),
+		Variable:       color.New(color.Underline),
+		Autosuggestion: color.New(color.Faint),
+		Completion:     color.New(color.Faint),
+		TableHeader:    color.New(color.Bold),
+	}
+}
+
+// themes are the bundled themes by name.
+var themes = map[string]func() *Theme{
+	"default":    DefaultTheme,
+	"dark":       DarkTheme,
+	"light":      LightTheme,
+	"monochrome": MonochromeTheme,
+}
+
+// ThemeByName returns the bundled theme with the name:
+// default, dark, light or monochrome.
+func ThemeByName(name string) (*Theme, bool) {
+	f, ok := themes[name]
+	if !ok {
+		return nil, false
+	}
+	return f(), true
+}
+
+// LoadTheme reads a theme file. Each line sets the color of an element,
+// like in the rc file: element attributes...
+//
+//	# comment
+//	prompt cyan bold
+//	error hired
+func LoadTheme(path string) (*Theme, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	t := &Theme{}
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		c, err := parseColor(fields[1:])
+		if err == nil {
+			err = t.set(fields[0], c)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+	}
+	return t, sc.Err()
+}
+
+// set the color of the element.
+func (t *Theme) set(element string, c *color.Color) error {
+	f := t.field(element)
+	if f == nil {
+		return fmt.Errorf("unknown color element '%s'", element)
+	}
+	*f = c
+	return nil
+}
+
+// field returns the color of the element. Nil, if the element is unknown.
+func (t *Theme) field(element string) **color.Color {
+	switch element {
+	case "prompt":
+		return &t.Prompt
+	case "multiprompt":
+		return &t.MultiPrompt
+	case "logo":
+		return &t.ASCIILogo
+	case "error":
+		return &t.Error
+	case "warning":
+		return &t.Warning
+	case "headline":
+		return &t.HelpHeadline
+	case "command":
+		return &t.Command
+	case "flag":
+		return &t.Flag
+	case "string":
+		return &t.String
+	case "variable":
+		return &t.Variable
+	case "autosuggestion":
+		return &t.Autosuggestion
+	case "completion":
+		return &t.Completion
+	case "tableheader":
+		return &t.TableHeader
+	default:
+		return nil
+	}
+}
+
+// colorField returns the config color of the element. Nil, if the element is unknown.
+func (c *Config) colorField(element string) **color.Color {
+	switch element {
+	case "prompt":
+		return &c.PromptColor
+	case "multiprompt":
+		return &c.MultiPromptColor
+	case "logo":
+		return &c.ASCIILogoColor
+	case "error":
+		return &c.ErrorColor
+	case "warning":
+		return &c.WarningColor
+	case "headline":
+		return &c.HelpHeadlineColor
+	case "command":
+		return &c.CommandColor
+	case "flag":
+		return &c.FlagColor
+	case "string":
+		return &c.StringColor
+	case "variable":
+		return &c.VariableColor
+	case "autosuggestion":
+		return &c.AutosuggestionColor
+	case "completion":
+		return &c.CompletionColor
+	case "tableheader":
+		return &c.TableHeaderColor
+	default:
+		return nil
+	}
+}
+
+// apply sets the config colors to the colors of the theme, which are not nil.
+// Colors, which are already set, are only replaced, if override is true.
+func (t *Theme) apply(c *Config, override bool) {
+	for _, e := range themeElements {
+		tc, cc := t.field(e), c.colorField(e)
+		if *tc != nil && (override || *cc == nil) {
+			*cc = *tc
+		}
+	}
+}
+
+// SetTheme applies the colors of the theme. Nil colors of the theme are not changed,
+// except for the colors derived from them like on the config defaults.
+func (a *App) SetTheme(t *Theme) {
+	c := a.config
+	t.apply(c, true)
+	if t.Prompt != nil && t.MultiPrompt == nil {
+		c.MultiPromptColor = c.PromptColor
+	}
+	if t.Prompt != nil && t.ASCIILogo == nil {
+		c.ASCIILogoColor = c.PromptColor
+	}
+	if t.HelpHeadline != nil && t.TableHeader == nil {
+		c.TableHeaderColor = c.HelpHeadlineColor
+	}
+	a.SetDefaultPrompt()
+}
+
+// colorDisabled returns true, if colors should be disabled, because the
+// NO_COLOR environment variable is set or the output is not a terminal.
+func (a *App) colorDisabled() bool {
+	if len(os.Getenv("NO_COLOR")) > 0 {
+		return true
+	} else if a.in == nil {
+		return false
+	}
+
+	if f, ok := a.in.Config.Stdout.(*os.File); ok {
+		return !term.IsTerminal(int(f.Fd()))
+	}
+	return !a.in.Config.FuncIsTerminal()
+}
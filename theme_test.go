@@ -0,0 +1,110 @@
+package grumble
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// ---------------------------------------------------------------------------
+// TestTheme
+// ---------------------------------------------------------------------------
+
+func TestTheme(t *testing.T) {
+	// Explicit colors take precedence over the theme and the default theme
+	// fills the remaining ones.
+	flag := color.New(color.FgRed)
+	a := New(&Config{Name: "app", Theme: LightTheme(), FlagColor: flag})
+	c := a.config
+
+	if c.FlagColor != flag {
+		t.Error("expected the explicit flag color")
+	}
+	if !c.PromptColor.Equals(color.New(color.FgBlue, color.Bold)) || c.MultiPromptColor != c.PromptColor {
+		t.Error("expected the prompt color of the theme")
+	}
+	if !c.TableHeaderColor.Equals(color.New(color.Bold)) {
+		t.Error("expected the table header color of the theme")
+	}
+
+	a = New(&Config{Name: "app", Theme: &Theme{Error: color.New(color.FgBlue)}})
+	c = a.config
+	if !c.ErrorColor.Equals(color.New(color.FgBlue)) || !c.WarningColor.Equals(color.New(color.FgYellow, color.Bold)) {
+		t.Error("expected the theme and default colors")
+	}
+
+	// Applying a theme replaces the colors.
+	a.SetTheme(DarkTheme())
+	if !c.PromptColor.Equals(color.New(color.FgHiCyan, color.Bold)) || c.ASCIILogoColor != c.PromptColor {
+		t.Error("expected the prompt color of the dark theme")
+	}
+	if !c.ErrorColor.Equals(color.New(color.FgHiRed, color.Bold)) {
+		t.Error("expected the error color of the dark theme")
+	}
+
+	for _, name := range []string{"default", "dark", "light", "monochrome"} {
+		if _, ok := ThemeByName(name); !ok {
+			t.Errorf("missing theme %q", name)
+		}
+	}
+	if _, ok := ThemeByName("neon"); ok {
+		t.Error("unexpected theme")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestLoadTheme
+// ---------------------------------------------------------------------------
+
+func TestLoadTheme(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "theme")
+	err := os.WriteFile(file, []byte("# my theme\nprompt cyan bold\n\ncompletion faint\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	th, err := LoadTheme(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !th.Prompt.Equals(color.New(color.FgCyan, color.Bold)) || !th.Completion.Equals(color.New(color.Faint)) || th.Error != nil {
+		t.Errorf("unexpected theme %+v", th)
+	}
+
+	err = os.WriteFile(file, []byte("prompt cyan\nbutton red\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadTheme(file); err == nil || !strings.HasSuffix(err.Error(), "theme:2: unknown color element 'button'") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// Themes can be set in the rc file.
+	a := New(&Config{Name: "app"})
+	if err = a.runRCLine("theme monochrome"); err != nil || !a.config.FlagColor.Equals(color.New(color.Italic)) {
+		t.Errorf("expected the monochrome theme: %v", err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestColorDisabled
+// ---------------------------------------------------------------------------
+
+func TestColorDisabled(t *testing.T) {
+	a := New(&Config{Name: "app"})
+	newScriptedShell(t, a)
+
+	// The scripted output is not a terminal.
+	if !a.colorDisabled() {
+		t.Error("expected colors to be disabled without a terminal")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if !New(&Config{Name: "app"}).colorDisabled() {
+		t.Error("expected colors to be disabled by NO_COLOR")
+	}
+}
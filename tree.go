@@ -0,0 +1,92 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// TreeNode is a node of a tree written with PrintTree.
+type TreeNode struct {
+	// Text of the node. Multiple lines are indented below the first one.
+	Text string
+
+	// Color of the text.
+	Color *color.Color
+
+	// Children of the node.
+	Children []*TreeNode
+}
+
+// Add appends a child node with the text and returns it.
+func (n *TreeNode) Add(text string) *TreeNode {
+	c := &TreeNode{Text: text}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// PrintTree writes the tree to the app output.
+func (a *App) PrintTree(root *TreeNode) error {
+	_, err := a.Write([]byte(a.formatTree(root)))
+	return err
+}
+
+// PrintTree writes the tree to the command output. See App.PrintTree.
+func (c *Context) PrintTree(root *TreeNode) error {
+	_, err := c.Write([]byte(c.App.formatTree(root)))
+	return err
+}
+
+// formatTree returns the tree with its branches drawn.
+func (a *App) formatTree(root *TreeNode) string {
+	var b strings.Builder
+	a.writeTreeNode(&b, root, "", "")
+	return b.String()
+}
+
+// writeTreeNode writes the node with the prefix of its first line and
+// of the following lines, which is extended for its children.
+func (a *App) writeTreeNode(b *strings.Builder, n *TreeNode, first, rest string) {
+	for i, l := range strings.Split(n.Text, "\n") {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if len(n.Children) > 0 && i > 0 {
+			prefix += "│ "
+		}
+		b.WriteString(prefix + a.colorize(n.Color, l) + "\n")
+	}
+
+	for i, c := range n.Children {
+		if i == len(n.Children)-1 {
+			a.writeTreeNode(b, c, rest+"└── ", rest+"    ")
+		} else {
+			a.writeTreeNode(b, c, rest+"├── ", rest+"│   ")
+		}
+	}
+}
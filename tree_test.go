@@ -0,0 +1,34 @@
+package grumble
+
+import "testing"
+
+// ---------------------------------------------------------------------------
+// TestTree
+// ---------------------------------------------------------------------------
+
+func TestTree(t *testing.T) {
+	a := newTestApp(t, &Config{Name: "app", NoColor: true})
+
+	root := &TreeNode{Text: "cluster"}
+	eu := root.Add("eu")
+	eu.Add("api")
+	eu.Add("web\nv1.2")
+	root.Add("us").Add("db")
+
+	want := "" +
+		"cluster\n" +
+		"├── eu\n" +
+		"│   ├── api\n" +
+		"│   └── web\n" +
+		"│       v1.2\n" +
+		"└── us\n" +
+		"    └── db\n"
+
+	b := newScriptedShell(t, a)
+	if err := a.PrintTree(root); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, b.String())
+	}
+}
@@ -0,0 +1,99 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"fmt"
+)
+
+// ValueMap is implemented by FlagMap and ArgMap and allows to access
+// their values with the generic Get and Lookup functions.
+type ValueMap interface {
+	// Has returns true, if a value for the given name is present.
+	Has(name string) bool
+
+	// IsSet returns true, if the value for the given name has been
+	// passed explicitly and is not a default value.
+	IsSet(name string) bool
+
+	// Names returns the sorted names of all present values.
+	Names() []string
+
+	lookupValue(name string) (v interface{}, ok bool)
+	kind() string
+}
+
+// Get returns the value with the given name converted to type T.
+// An error is returned, if the value is not present or not of type T.
+// Optional list arguments without a default value return the zero value of T.
+func Get[T any](m ValueMap, name string) (T, error) {
+	var zero T
+
+	v, ok := m.lookupValue(name)
+	if !ok {
+		return zero, fmt.Errorf("%s '%s' not registered", m.kind(), name)
+	} else if v == nil {
+		return zero, nil
+	}
+
+	t, ok := v.(T)
+	if ok {
+		return t, nil
+	}
+
+	// List flags are stored as []interface{}.
+	if l, isList := v.([]interface{}); isList {
+		if t, ok = convertList[T](l); ok {
+			return t, nil
+		}
+	}
+
+	return zero, fmt.Errorf("failed to assert %s '%s' of type %T to %T", m.kind(), name, v, zero)
+}
+
+// Lookup returns the value with the given name converted to type T.
+// The boolean is false, if the value is not present or not of type T.
+func Lookup[T any](m ValueMap, name string) (T, bool) {
+	v, err := Get[T](m, name)
+	return v, err == nil
+}
+
+// convertList converts the elements of l to the slice type T.
+// Returns false, if T is not a supported slice type or an element
+// does not match the element type of T.
+func convertList[T any](l []interface{}) (t T, ok bool) {
+	if p, isStringList := any(&t).(*[]string); isStringList {
+		s := make([]string, len(l))
+		for i, v := range l {
+			s[i], ok = v.(string)
+			if !ok {
+				return
+			}
+		}
+		*p = s
+		return t, true
+	}
+	return
+}
@@ -0,0 +1,116 @@
+package grumble
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// TestGetFlag
+// ---------------------------------------------------------------------------
+
+func TestGetFlag(t *testing.T) {
+	f := &Flags{}
+	f.String("n", "name", "def", "help")
+	f.Duration("t", "timeout", time.Second, "help")
+	f.StringList("l", "label", []string{"a"}, "help")
+
+	res := newFlagMap()
+	_, err := f.parse([]string{"--name", "x", "-l", "b"}, res)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	t.Run("string", func(t *testing.T) {
+		v, err := Get[string](res, "name")
+		if err != nil || v != "x" {
+			t.Fatalf("expected 'x', got '%s' (%v)", v, err)
+		}
+	})
+
+	t.Run("duration default", func(t *testing.T) {
+		v, err := Get[time.Duration](res, "timeout")
+		if err != nil || v != time.Second {
+			t.Fatalf("expected 1s, got %v (%v)", v, err)
+		}
+	})
+
+	t.Run("string list", func(t *testing.T) {
+		v, err := Get[[]string](res, "label")
+		if err != nil || !reflect.DeepEqual(v, []string{"b"}) {
+			t.Fatalf("expected [b], got %v (%v)", v, err)
+		}
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		if _, err := Get[int](res, "name"); err == nil {
+			t.Fatal("expected error for type mismatch")
+		}
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		if _, err := Get[string](res, "missing"); err == nil {
+			t.Fatal("expected error for missing flag")
+		}
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		if v, ok := Lookup[string](res, "name"); !ok || v != "x" {
+			t.Fatalf("expected 'x', got '%s'", v)
+		}
+		if _, ok := Lookup[bool](res, "name"); ok {
+			t.Fatal("expected lookup to fail for type mismatch")
+		}
+	})
+
+	t.Run("has and is set", func(t *testing.T) {
+		if !res.Has("timeout") || res.IsSet("timeout") {
+			t.Fatal("expected timeout to be present but not set")
+		}
+		if !res.Has("name") || !res.IsSet("name") {
+			t.Fatal("expected name to be present and set")
+		}
+		if res.Has("missing") || res.IsSet("missing") {
+			t.Fatal("expected missing to be absent")
+		}
+	})
+
+	t.Run("names", func(t *testing.T) {
+		if n := res.Names(); !reflect.DeepEqual(n, []string{"label", "name", "timeout"}) {
+			t.Fatalf("expected [label name timeout], got %v", n)
+		}
+	})
+}
+
+// ---------------------------------------------------------------------------
+// TestGetArg
+// ---------------------------------------------------------------------------
+
+func TestGetArg(t *testing.T) {
+	var a Args
+	a.Int("count", "a count")
+	a.StringList("names", "some names")
+
+	res := make(ArgMap)
+	_, err := a.parse([]string{"3"}, res)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	if v, err := Get[int](res, "count"); err != nil || v != 3 {
+		t.Fatalf("expected 3, got %d (%v)", v, err)
+	}
+	if v, err := Get[[]string](res, "names"); err != nil || v != nil {
+		t.Fatalf("expected nil list, got %v (%v)", v, err)
+	}
+	if _, err := Get[string](res, "count"); err == nil {
+		t.Fatal("expected error for type mismatch")
+	}
+	if !res.IsSet("count") || res.IsSet("names") {
+		t.Fatal("expected only count to be set")
+	}
+	if n := res.Names(); !reflect.DeepEqual(n, []string{"count", "names"}) {
+		t.Fatalf("expected [count names], got %v", n)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2018 Roland Singer [roland.singer@deserbit.com]
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+package grumble
+
+import (
+	"strings"
+
+	"github.com/desertbit/readline"
+)
+
+// minWrapWidth is the minimum width text is wrapped to.
+// Narrower text is not wrapped at all.
+const minWrapWidth = 20
+
+// textWidth returns the display width of the text.
+// Color escape sequences are ignored and wide characters count twice.
+func textWidth(s string) int {
+	var r readline.Runes
+	return r.WidthAll(r.ColorFilter([]rune(s)))
+}
+
+// wrapText word-wraps each line of the text to the width.
+// The text is returned unchanged, if the width is too small.
+func wrapText(s string, width int) string {
+	if width < minWrapWidth {
+		return s
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.Join(wrapLine(l, width), "\n")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapLine word-wraps a single line to the width. Leading white space is
+// kept on the first line. Words longer than the width are not split.
+func wrapLine(s string, width int) []string {
+	if textWidth(s) <= width {
+		return []string{s}
+	}
+
+	var (
+		lines []string
+		cur   = s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+		empty = true
+	)
+	for _, w := range strings.Fields(s) {
+		if !empty && textWidth(cur)+1+textWidth(w) > width {
+			lines = append(lines, cur)
+			cur, empty = "", true
+		}
+		if !empty {
+			cur += " "
+		}
+		cur += w
+		empty = false
+	}
+	return append(lines, cur)
+}
+
+// hangText word-wraps the text, which starts at column indent, to the width.
+// Continuation lines are indented to the same column.
+func hangText(s string, indent, width int) string {
+	if width-indent < minWrapWidth {
+		return s
+	}
+	pad := "\n" + strings.Repeat(" ", indent)
+	return strings.ReplaceAll(wrapText(s, width-indent), "\n", pad)
+}
+
+// truncate the text to the width. The width is not limited, if zero.
+func truncate(s string, width int) string {
+	if width <= 0 || textWidth(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && textWidth(string(r))+1 > width {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "…"
+}
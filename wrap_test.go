@@ -0,0 +1,76 @@
+package grumble
+
+import (
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// TestWrapText
+// ---------------------------------------------------------------------------
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"short text", 20, "short text"},
+		{"the quick brown fox jumps over the lazy dog", 20, "the quick brown fox\njumps over the lazy\ndog"},
+		{"first paragraph is long enough\nsecond", 20, "first paragraph is\nlong enough\nsecond"},
+		{"  indented text that needs wrapping", 20, "  indented text that\nneeds wrapping"},
+		{"averyveryverylongwordthatdoesnotfit x", 20, "averyveryverylongwordthatdoesnotfit\nx"},
+		{"the quick brown fox jumps over the lazy dog", 10, "the quick brown fox jumps over the lazy dog"},
+		{"the quick brown fox jumps over the lazy dog", 0, "the quick brown fox jumps over the lazy dog"},
+		{"日本語 日本語 日本語 日本語", 20, "日本語 日本語 日本語\n日本語"},
+	}
+
+	for _, tt := range tests {
+		if got := wrapText(tt.in, tt.width); got != tt.want {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestHangText(t *testing.T) {
+	got := hangText("the quick brown fox jumps over the lazy dog", 10, 32)
+	want := "the quick brown fox\n          jumps over the lazy\n          dog"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	tests := map[string]int{
+		"":                   0,
+		"abc":                3,
+		"日本":                 4,
+		"\x1b[31mred\x1b[0m": 3,
+	}
+	for s, want := range tests {
+		if got := textWidth(s); got != want {
+			t.Errorf("textWidth(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TestTruncate
+// ---------------------------------------------------------------------------
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{s: "hello", width: 0, want: "hello"},
+		{s: "hello", width: 5, want: "hello"},
+		{s: "hello", width: 4, want: "hel…"},
+		{s: "日本語", width: 4, want: "日…"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.width); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}